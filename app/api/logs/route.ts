@@ -0,0 +1,93 @@
+import { NextResponse } from "next/server"
+import { readFile, appendFile, access } from "fs/promises"
+import { join } from "path"
+
+// Audit log written by the Go bot (bot/data/auditlog.jsonl), one JSON entry per line
+
+interface AuditEntry {
+  time: string
+  actor: string
+  target: string
+  group: string
+  action: string
+  reason: string
+  source: string
+}
+
+async function findLogFile(): Promise<string> {
+  const botDataDir = join(process.cwd(), "..", "bot", "data")
+  const altDir = join(process.cwd(), "bot", "data")
+
+  for (const dir of [botDataDir, altDir]) {
+    try {
+      await access(dir)
+      return join(dir, "auditlog.jsonl")
+    } catch {
+      continue
+    }
+  }
+  return join(altDir, "auditlog.jsonl")
+}
+
+export async function GET(request: Request) {
+  const { searchParams } = new URL(request.url)
+  const group = searchParams.get("group")
+  const action = searchParams.get("action")
+  const limit = Math.min(Number(searchParams.get("limit")) || 200, 1000)
+
+  let raw = ""
+  try {
+    raw = await readFile(await findLogFile(), "utf-8")
+  } catch {
+    return NextResponse.json([])
+  }
+
+  const entries: AuditEntry[] = []
+  for (const line of raw.split("\n")) {
+    if (!line.trim()) continue
+    try {
+      entries.push(JSON.parse(line))
+    } catch {
+      continue
+    }
+  }
+
+  const filtered = entries
+    .filter((e) => (!group || e.group === group) && (!action || e.action === action))
+    .reverse()
+    .slice(0, limit)
+
+  return NextResponse.json(filtered)
+}
+
+// Actions the panel itself performs; the actor is always "Painel" so the panel
+// cannot write entries in the name of the bot or of a group member
+const PANEL_ACTIONS = new Set(["listanegra", "tirardalista"])
+
+export async function POST(request: Request) {
+  try {
+    const body = await request.json()
+    const { target, group, action, reason } = body
+
+    if (!PANEL_ACTIONS.has(action)) {
+      return NextResponse.json({ error: "Invalid action" }, { status: 400 })
+    }
+
+    const now = new Date()
+    const pad = (n: number) => n.toString().padStart(2, "0")
+    const entry: AuditEntry = {
+      time: `${now.getFullYear()}-${pad(now.getMonth() + 1)}-${pad(now.getDate())} ${pad(now.getHours())}:${pad(now.getMinutes())}:${pad(now.getSeconds())}`,
+      actor: "Painel",
+      target: target || "",
+      group: group || "",
+      action,
+      reason: reason || "",
+      source: "painel",
+    }
+
+    await appendFile(await findLogFile(), JSON.stringify(entry) + "\n", "utf-8")
+    return NextResponse.json({ success: true })
+  } catch {
+    return NextResponse.json({ error: "Invalid JSON body" }, { status: 400 })
+  }
+}
@@ -18,6 +18,7 @@ import {
   getBlacklist,
   addToBlacklist,
   removeFromBlacklist,
+  logPanelAction,
   generateId,
   type BlacklistEntry,
 } from "@/lib/store"
@@ -49,6 +50,7 @@ export default function BlacklistPage() {
       date: new Date().toISOString(),
       addedBy: "Painel",
     })
+    logPanelAction({ action: "listanegra", target: form.number, reason: form.reason || "Sem motivo informado" })
     setDialogOpen(false)
     setForm({ number: "", reason: "" })
     reload()
@@ -125,7 +127,7 @@ export default function BlacklistPage() {
                 </div>
                 <div className="flex items-center gap-3">
                   <span className="text-xs text-muted-foreground">{new Date(b.date).toLocaleDateString("pt-BR")}</span>
-                  <Button variant="ghost" size="icon" className="h-8 w-8 text-muted-foreground hover:text-destructive" onClick={() => { removeFromBlacklist(b.id); reload() }}>
+                  <Button variant="ghost" size="icon" className="h-8 w-8 text-muted-foreground hover:text-destructive" onClick={() => { removeFromBlacklist(b.id); logPanelAction({ action: "tirardalista", target: b.number }); reload() }}>
                     <Trash2 className="h-4 w-4" />
                   </Button>
                 </div>
@@ -0,0 +1,105 @@
+"use client"
+
+import { useEffect, useState, useCallback } from "react"
+import PanelLayout from "@/components/panel-layout"
+import { Card, CardContent } from "@/components/ui/card"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { History, RefreshCw, Search } from "lucide-react"
+import { getAuditLog, type AuditEntry } from "@/lib/store"
+
+const sourceLabels: Record<AuditEntry["source"], string> = {
+  comando: "Comando",
+  automatico: "Automatico",
+  painel: "Painel",
+}
+
+export default function LogsPage() {
+  const [entries, setEntries] = useState<AuditEntry[]>([])
+  const [search, setSearch] = useState("")
+  const [loading, setLoading] = useState(true)
+
+  const reload = useCallback(async () => {
+    setLoading(true)
+    setEntries(await getAuditLog({ limit: 500 }))
+    setLoading(false)
+  }, [])
+
+  useEffect(() => {
+    reload()
+  }, [reload])
+
+  const q = search.toLowerCase()
+  const filtered = entries.filter(
+    (e) =>
+      e.action.toLowerCase().includes(q) ||
+      e.actor.includes(search) ||
+      e.target.includes(search) ||
+      e.group.includes(search) ||
+      e.reason.toLowerCase().includes(q)
+  )
+
+  return (
+    <PanelLayout>
+      <div className="mb-6 flex flex-col gap-4 sm:flex-row sm:items-center sm:justify-between">
+        <div>
+          <h1 className="text-2xl font-bold text-foreground">Historico</h1>
+          <p className="text-sm text-muted-foreground">Log de auditoria das acoes de moderacao</p>
+        </div>
+        <Button variant="outline" size="sm" onClick={reload}>
+          <RefreshCw className="mr-2 h-4 w-4" />
+          Atualizar
+        </Button>
+      </div>
+
+      <div className="relative mb-6">
+        <Search className="absolute left-3 top-1/2 h-4 w-4 -translate-y-1/2 text-muted-foreground" />
+        <Input className="border-border bg-card pl-10 text-foreground" placeholder="Buscar acao, numero ou grupo..." value={search} onChange={(e) => setSearch(e.target.value)} />
+      </div>
+
+      {loading ? (
+        <div className="flex h-[40vh] items-center justify-center">
+          <div className="h-8 w-8 animate-spin rounded-full border-2 border-primary border-t-transparent" />
+        </div>
+      ) : filtered.length === 0 ? (
+        <Card className="border-border bg-card">
+          <CardContent className="flex flex-col items-center justify-center py-12">
+            <History className="mb-4 h-12 w-12 text-muted-foreground" />
+            <p className="text-lg font-medium text-foreground">Nenhum registro</p>
+          </CardContent>
+        </Card>
+      ) : (
+        <div className="flex flex-col gap-3">
+          {filtered.map((e, i) => (
+            <Card key={`${e.time}-${i}`} className="border-border bg-card">
+              <CardContent className="flex items-center justify-between p-4">
+                <div className="flex items-center gap-3">
+                  <div className="flex h-10 w-10 items-center justify-center rounded-lg bg-primary/10">
+                    <History className="h-5 w-5 text-primary" />
+                  </div>
+                  <div>
+                    <p className="text-sm font-medium text-foreground">
+                      {e.action}
+                      <span className="font-mono text-muted-foreground">
+                        {" "}
+                        {e.actor}
+                        {e.target && ` -> ${e.target}`}
+                      </span>
+                    </p>
+                    {e.reason && <p className="text-xs text-muted-foreground">{e.reason}</p>}
+                    {e.group && <p className="text-xs text-muted-foreground font-mono">{e.group}</p>}
+                  </div>
+                </div>
+                <div className="flex items-center gap-3">
+                  <Badge variant="secondary">{sourceLabels[e.source] ?? e.source}</Badge>
+                  <span className="text-xs text-muted-foreground">{e.time}</span>
+                </div>
+              </CardContent>
+            </Card>
+          ))}
+        </div>
+      )}
+    </PanelLayout>
+  )
+}
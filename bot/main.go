@@ -1,17 +1,28 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // fusos do #sethorario mesmo sem tzdata no sistema
+	"unicode"
+	"unicode/utf8"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/mdp/qrterminal/v3"
@@ -59,6 +70,70 @@ type GroupConfig struct {
 	AutoDL       bool   `json:"auto_dl"`
 	AntiBot      bool   `json:"anti_bot"`
 	ModoRPG      bool   `json:"modo_rpg"`
+
+	CmdRoles     map[string]string `json:"cmd_roles"`     // comando -> cargo minimo (sobrescreve o padrao)
+	DisabledCmds []string          `json:"disabled_cmds"` // comandos ou categorias bloqueados no grupo
+	Messages     map[string]string `json:"messages"`      // tipo -> modelo personalizado (#setmsg)
+	ReportGroup  string            `json:"report_group"`  // grupo de admins que recebe os #report
+
+	VoteKick        bool `json:"votekick"`         // desligado ate um admin usar #votekickcfg on
+	VoteKickQuorum  int  `json:"votekick_quorum"`  // votos minimos (0 = padrao)
+	VoteKickPercent int  `json:"votekick_percent"` // % de "sim" necessaria (0 = padrao)
+	VoteKickMinutes int  `json:"votekick_minutes"` // duracao da votacao (0 = padrao)
+
+	SlowMode int `json:"slow_mode"` // segundos entre mensagens de cada membro (0 = desligado)
+
+	Schedule *GroupSchedule `json:"schedule,omitempty"` // abertura/fechamento automatico
+	Lock     *GroupLock     `json:"lock,omitempty"`     // configuracoes aprovadas (#travarconfig)
+
+	AnnounceAdmins  bool     `json:"announce_admins"`  // avisar promocoes/rebaixamentos
+	ProtectedAdmins []string `json:"protected_admins"` // admins que nao podem ser rebaixados
+
+	MediaFilters map[string]string `json:"media_filters"` // tipo de midia -> apagar/advertir/remover
+
+	AntiTrava   bool         `json:"anti_trava"`
+	TravaLimits *TravaLimits `json:"trava_limits,omitempty"` // nil = limites padrao
+
+	MentionRule *MentionRule `json:"mention_rule,omitempty"` // anti-marcacao em massa
+
+	AntiDelete        string `json:"anti_delete"`         // "" desligado, grupo (repostar) ou admins
+	AntiDeleteMinutes int    `json:"anti_delete_minutes"` // por quanto tempo lembrar as mensagens
+
+	ReactionActions map[string]string `json:"reaction_actions"` // emoji -> apagar/advertir/mutar/banir (nil = desligado)
+}
+
+type MentionRule struct {
+	PerMessage int    `json:"per_message"` // membros distintos por mensagem (0 = sem limite)
+	PerWindow  int    `json:"per_window"`  // membros distintos dentro da janela (0 = sem limite)
+	Window     int    `json:"window"`      // minutos
+	Action     string `json:"action"`      // apagar, advertir, remover
+}
+
+// Limites do anti-trava. Porcentagens sao sobre o total de caracteres.
+type TravaLimits struct {
+	MaxLength    int `json:"max_length"`    // caracteres (texto + vCards)
+	MaxCombining int `json:"max_combining"` // % de acentos combinantes
+	MaxInvisible int `json:"max_invisible"` // % de caracteres invisiveis/RTL
+	MaxMentions  int `json:"max_mentions"`
+	MaxContacts  int `json:"max_contacts"`
+}
+
+type GroupLock struct {
+	Name     string `json:"name"`
+	Topic    string `json:"topic"`
+	Announce bool   `json:"announce"`
+	Locked   bool   `json:"locked"`
+	Demote   bool   `json:"demote"` // rebaixar o admin que alterar sem autorizacao
+}
+
+type GroupSchedule struct {
+	Close     string `json:"close"`      // HH:MM
+	Open      string `json:"open"`       // HH:MM
+	Days      []int  `json:"days"`       // dias (0 = domingo) em que o grupo fecha; vazio = todos
+	Timezone  string `json:"timezone"`   // ex: America/Manaus
+	CloseMsg  string `json:"close_msg"`  // aviso ao fechar (opcional)
+	OpenMsg   string `json:"open_msg"`   // aviso ao abrir (opcional)
+	LastState string `json:"last_state"` // ultimo estado aplicado: fechado/aberto
 }
 
 type Rental struct {
@@ -84,10 +159,67 @@ type Warning struct {
 }
 
 type BlacklistEntry struct {
-	Number  string `json:"number"`
-	Reason  string `json:"reason"`
-	Date    string `json:"date"`
-	AddedBy string `json:"added_by"`
+	Number    string `json:"number"`
+	Reason    string `json:"reason"`
+	Date      string `json:"date"`
+	AddedBy   string `json:"added_by"`
+	Scope     string `json:"scope,omitempty"`      // global (padrao), grupo ou dono
+	GroupJID  string `json:"group_jid,omitempty"`  // escopo grupo
+	Owner     string `json:"owner,omitempty"`      // escopo dono: numero do dono (aluguel) dos grupos
+	ExpiresAt string `json:"expires_at,omitempty"` // vazio = permanente
+	Readd     bool   `json:"readd,omitempty"`      // readicionar ao grupo quando expirar
+	Source    string `json:"source,omitempty"`     // vazio = local; senao arquivo/URL de onde veio
+	Evidence  string `json:"evidence,omitempty"`   // deteccao automatica: grupos, conteudo e hash
+}
+
+// Escopos da lista negra
+const (
+	ScopeGlobal = "global"
+	ScopeGroup  = "grupo"
+	ScopeOwner  = "dono"
+)
+
+type Report struct {
+	ID         int    `json:"id"`
+	GroupJID   string `json:"group_jid"`
+	Reporter   string `json:"reporter"`
+	Reported   string `json:"reported"`
+	MessageID  string `json:"message_id"`
+	Text       string `json:"text"`
+	Reason     string `json:"reason"`
+	Date       string `json:"date"`
+	Status     string `json:"status"` // aberto, resolvido
+	ResolvedBy string `json:"resolved_by,omitempty"`
+	Action     string `json:"action,omitempty"`
+}
+
+// Apelacao de quem esta na lista negra, enviada no privado com #apelar
+type Appeal struct {
+	ID         int      `json:"id"`
+	Number     string   `json:"number"`
+	Text       string   `json:"text"`
+	Date       string   `json:"date"`
+	Keys       []string `json:"keys"`   // entradas da lista negra contestadas
+	Groups     []string `json:"groups"` // grupos das entradas de escopo grupo
+	Status     string   `json:"status"` // aberto, aceito, negado
+	ResolvedBy string   `json:"resolved_by,omitempty"`
+}
+
+// Origem de uma acao registrada no log de auditoria
+const (
+	SourceCommand = "comando"
+	SourceAuto    = "automatico"
+	SourcePanel   = "painel"
+)
+
+type AuditEntry struct {
+	Time   string `json:"time"`
+	Actor  string `json:"actor"`
+	Target string `json:"target"`
+	Group  string `json:"group"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Source string `json:"source"`
 }
 
 type BotData struct {
@@ -95,12 +227,35 @@ type BotData struct {
 	Groups     map[string]*GroupConfig      `json:"groups"`
 	Rentals    []Rental                     `json:"rentals"`
 	Warnings   map[string][]Warning         `json:"warnings"`
-	Blacklist  map[string]BlacklistEntry     `json:"blacklist"`
+	Blacklist  map[string]BlacklistEntry    `json:"blacklist"`
 	BadWords   map[string][]string          `json:"bad_words"`
 	Notes      map[string][]string          `json:"notes"`
 	MutedUsers map[string]map[string]bool   `json:"muted_users"`
 	AfkUsers   map[string]string            `json:"afk_users"`
 	Roles      map[string]map[string]string `json:"roles"`
+
+	DisabledCmds []string `json:"disabled_cmds"` // comandos em manutencao (todos os grupos)
+	Reports      []Report `json:"reports"`
+	ReportSeq    int      `json:"report_seq"`
+
+	BlacklistFeeds     []string `json:"blacklist_feeds"`     // URLs/arquivos com listas compartilhadas
+	BlacklistOverrides []string `json:"blacklist_overrides"` // numeros liberados localmente apesar das listas
+
+	AntiCall   *AntiCallConfig `json:"anti_call,omitempty"` // nil = configuracao padrao
+	CallCounts map[string]int  `json:"call_counts"`         // ligacoes rejeitadas por numero
+
+	MessageSpill bool `json:"message_spill"` // guardar em SQLite as mensagens que saem do cache
+
+	Appeals   []Appeal `json:"appeals"`
+	AppealSeq int      `json:"appeal_seq"`
+
+	CrossSpam *CrossSpamConfig `json:"cross_spam,omitempty"` // nil = configuracao padrao
+}
+
+type AntiCallConfig struct {
+	Enabled    bool `json:"enabled"`
+	BlockAfter int  `json:"block_after"` // bloquear apos N ligacoes (0 = nunca)
+	Notify     bool `json:"notify"`      // avisar o dono a cada ligacao
 }
 
 var (
@@ -127,6 +282,11 @@ func main() {
 	}
 
 	botData = loadBotData()
+	if botData.MessageSpill {
+		if err := openMessageSpill(); err != nil {
+			fmt.Printf("[ERRO] Abrir cache de mensagens: %v\n", err)
+		}
+	}
 
 	dbLog := waLog.Stdout("Database", "WARN", true)
 	container, err := sqlstore.New(context.Background(), "sqlite3", "file:odinbot.db?_foreign_keys=on", dbLog)
@@ -212,6 +372,15 @@ func main() {
 	// Iniciar verificacao de alugueis expirados
 	go rentalChecker()
 
+	// Remover da lista negra os bans temporarios vencidos
+	go blacklistExpiryChecker()
+
+	// Sincronizar listas negras compartilhadas
+	go blacklistFeedPuller()
+
+	// Abertura/fechamento automatico dos grupos
+	go groupScheduler()
+
 	// Aguardar sinal para encerrar
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -238,6 +407,10 @@ func eventHandler(rawEvt interface{}) {
 			handleGroupEvent(v)
 		case *events.JoinedGroup:
 			handleJoinedGroup(v)
+		case *events.CallOffer:
+			handleCall(v.BasicCallMeta)
+		case *events.CallOfferNotice:
+			handleCall(v.BasicCallMeta)
 		case *events.Disconnected:
 			fmt.Println("[OdinBOT] Evento: Desconectado!")
 		}
@@ -248,26 +421,41 @@ func handleGroupEvent(evt *events.GroupInfo) {
 	groupJID := evt.JID.String()
 	cfg := getGroupConfig(groupJID)
 
+	// Nome, descricao e configuracoes travadas
+	if evt.Name != nil || evt.Topic != nil || evt.Announce != nil || evt.Locked != nil {
+		checkGroupTamper(evt)
+	}
+
+	// Promocoes e rebaixamentos
+	if len(evt.Promote) > 0 || len(evt.Demote) > 0 {
+		handleAdminChanges(evt)
+	}
+
 	// Member join
 	if evt.Join != nil && len(evt.Join) > 0 {
-		if cfg.Welcome {
-			for _, jid := range evt.Join {
-				if isBlacklisted(jid.User) {
-					removeMember(evt.JID, jid)
-					sendText(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s esta na lista negra e foi removido.", jid.User))
-					continue
-				}
-				if cfg.Antifake && !strings.HasPrefix(jid.User, "55") {
-					removeMember(evt.JID, jid)
-					sendText(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s removido (numero estrangeiro - anti-fake).", jid.User))
-					continue
-				}
-				msg := cfg.WelcomeMsg
-				msg = strings.ReplaceAll(msg, "{name}", "@"+jid.User)
-				msg = strings.ReplaceAll(msg, "{group}", cfg.Name)
-				msg = strings.ReplaceAll(msg, "{number}", jid.User)
-				sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]*\n\n%s", msg), []string{jid.User})
+		for _, jid := range evt.Join {
+			// Lista negra e antifake valem mesmo com as boas-vindas desligadas
+			if entry, ok := findBlacklistEntry(jid.User, groupJID); ok {
+				removeMember(evt.JID, jid)
+				logAction(groupJID, BotName, jid.User, "listanegra", "Entrou no grupo estando na lista negra ("+describeBlacklistScope(entry)+")", SourceAuto)
+				sendGroupTemplate(evt.JID, "listanegra", jid.User, map[string]string{"reason": "lista negra"})
+				continue
+			}
+			if cfg.Antifake && !strings.HasPrefix(jid.User, "55") {
+				removeMember(evt.JID, jid)
+				logAction(groupJID, BotName, jid.User, "antifake", "Numero estrangeiro", SourceAuto)
+				sendGroupTemplate(evt.JID, "antifake", jid.User, map[string]string{"reason": "numero estrangeiro"})
+				continue
 			}
+			recordMemberJoin(evt.JID, jid)
+			if !cfg.Welcome {
+				continue
+			}
+			msg := cfg.WelcomeMsg
+			msg = strings.ReplaceAll(msg, "{name}", "@"+jid.User)
+			msg = strings.ReplaceAll(msg, "{group}", cfg.Name)
+			msg = strings.ReplaceAll(msg, "{number}", jid.User)
+			sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]*\n\n%s", msg), []string{jid.User})
 		}
 	}
 
@@ -290,7 +478,51 @@ func handleMessage(msg *events.Message) {
 	chat := msg.Info.Chat
 	sender := msg.Info.Sender
 	isGroup := chat.Server == "g.us"
-	text := getMessageText(msg)
+
+	if reaction := msg.Message.GetReactionMessage(); reaction != nil {
+		if isGroup {
+			handleReaction(chat, sender, reaction)
+		}
+		return
+	}
+
+	if revoked := getRevokedID(msg.Message); revoked != "" {
+		if isGroup {
+			handleRevoke(chat, sender, revoked)
+		}
+		return
+	}
+
+	if edited, id := getEditedMessage(msg.Message); edited != nil {
+		if isGroup {
+			handleEditedMessage(chat, sender, edited, id)
+		}
+		return
+	}
+
+	content := extractMessage(msg.Message)
+	text := content.Text
+	storeMessage(msg, content)
+
+	// Anti-trava antes de tudo: a mensagem precisa sumir mesmo sem texto
+	if isGroup && checkTrava(chat, sender, msg.Info.ID, content) {
+		return
+	}
+	if isGroup && checkCrossGroupSpam(chat, sender, msg.Info.ID, content) {
+		return
+	}
+
+	if isGroup && checkSlowMode(msg) {
+		return
+	}
+
+	// Filtros de midia rodam antes do retorno de mensagens sem texto
+	if isGroup && checkMediaFilter(chat, sender, msg.Info.ID, content) {
+		return
+	}
+	if isGroup && checkMassMention(chat, sender, msg.Info.ID, content) {
+		return
+	}
 
 	if text == "" {
 		return
@@ -298,10 +530,11 @@ func handleMessage(msg *events.Message) {
 
 	isOwner := isOwnerNumber(sender.User)
 
-	// Verificar blacklist
-	if isBlacklisted(sender.User) && isGroup {
+	// Verificar blacklist (global, do dono e do grupo)
+	if entry, ok := findBlacklistEntry(sender.User, chat.String()); ok && isGroup {
 		removeMember(chat, sender)
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* Usuario %s esta na lista negra e foi removido.", sender.User))
+		logAction(chat.String(), BotName, sender.User, "listanegra", "Enviou mensagem estando na lista negra ("+describeBlacklistScope(entry)+")", SourceAuto)
+		sendGroupTemplate(chat, "listanegra", sender.User, map[string]string{"reason": "lista negra"})
 		return
 	}
 
@@ -309,28 +542,12 @@ func handleMessage(msg *events.Message) {
 	checkAfk(chat, sender, text)
 
 	if isGroup {
-		groupJID := chat.String()
-		cfg := getGroupConfig(groupJID)
-
-		// Anti-link
-		if cfg.Antilink && !isOwner && !isGroupAdmin(chat, sender) {
-			if containsLink(text) {
-				removeMember(chat, sender)
-				sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s removido por enviar link.", sender.User))
-				return
-			}
-		}
-
-		// Anti-palavrao
-		if cfg.AntiPalavrao && !isOwner && !isGroupAdmin(chat, sender) {
-			if containsBadWord(groupJID, text) {
-				addWarningAuto(groupJID, sender, "Palavra proibida detectada")
-				sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s cuidado com as palavras! Advertencia aplicada.", sender.User))
-				return
-			}
+		if moderateGroupMessage(chat, sender, text) {
+			return
 		}
 
 		// Only admin mode
+		cfg := getGroupConfig(chat.String())
 		if cfg.OnlyAdm && !isOwner && !isGroupAdmin(chat, sender) {
 			return
 		}
@@ -353,20 +570,30 @@ func handleMessage(msg *events.Message) {
 		args = strings.Join(parts[1:], " ")
 	}
 
+	// Comandos bloqueados no grupo ou em manutencao
+	if !isOwner {
+		if disabled, global := isCommandDisabled(chat.String(), isGroup, cmd); disabled {
+			if global {
+				sendText(chat, "*[OdinBOT]* Este comando esta em manutencao. Tente mais tarde.")
+			}
+			return
+		}
+	}
+
 	// Comandos de DONO (somente Erick Machine)
 	if isOwner {
 		switch cmd {
 		case "aluguel", "add_contrat":
-			cmdAluguel(chat, args)
+			cmdAluguel(chat, sender, args)
 			return
 		case "verificar_aluguel":
 			cmdVerificarAluguel(chat)
 			return
 		case "bcaluguel":
-			cmdBroadcastAluguel(chat, args)
+			cmdBroadcastAluguel(chat, sender, args)
 			return
 		case "bc":
-			cmdBroadcast(args)
+			cmdBroadcast(sender, args)
 			return
 		case "join":
 			cmdJoin(args)
@@ -382,7 +609,7 @@ func handleMessage(msg *events.Message) {
 			}
 			return
 		case "tirardalista":
-			cmdRemoveBlacklist(chat, args)
+			cmdRemoveBlacklist(chat, sender, args)
 			return
 		case "addgold":
 			return
@@ -391,24 +618,77 @@ func handleMessage(msg *events.Message) {
 		case "resetlevel":
 			return
 		case "nuke":
-			cmdNuke(chat)
+			cmdNuke(chat, sender)
 			return
 		case "grupos":
 			cmdListGroups(chat)
 			return
+		case "logs":
+			if strings.HasPrefix(args, "global") {
+				cmdLogs(chat, msg, args, true)
+				return
+			}
 		case "adddono":
 			return
 		case "cargo":
-			cmdSetRole(chat, msg, args)
+			cmdSetRole(chat, msg, sender, args)
+			return
+		case "manutencao":
+			cmdMaintenance(chat, sender, args)
+			return
+		case "apelacoes":
+			cmdListAppeals(chat, true)
+			return
+		case "aceitarapelo", "negarapelo":
+			cmdResolveAppeal(chat, sender, cmd == "aceitarapelo", args, true)
+			return
+		case "varrerlista":
+			cmdSweepBlacklist(chat, sender)
+			return
+		case "antiligar":
+			cmdAntiCall(chat, sender, args)
+			return
+		case "cachemsg":
+			cmdMessageCache(chat, sender, args)
+			return
+		case "spamcruzado":
+			cmdCrossSpam(chat, sender, args)
+			return
+		case "exportlista":
+			cmdExportBlacklist(chat, sender, args)
+			return
+		case "importlista":
+			cmdImportBlacklist(chat, msg, sender)
+			return
+		case "listafonte":
+			cmdBlacklistFeeds(chat, sender, args)
 			return
 		}
 	}
 
-	// Comandos ADM (admin do grupo ou dono)
-	if isGroup && (isOwner || isGroupAdmin(chat, sender)) {
+	// Permissoes por cargo (dono sempre pode)
+	if isGroup && !isOwner && !canRunCommand(chat, sender, cmd) {
+		return
+	}
+
+	// Comandos ADM (admin do grupo, cargo com permissao ou dono)
+	if isGroup {
 		switch cmd {
 		case "ban":
-			cmdBan(chat, msg)
+			cmdBan(chat, msg, sender, args)
+			return
+		case "tempban":
+			cmdTempBan(chat, msg, sender, args)
+			return
+		case "listanegragp", "blgp":
+			if args != "" || getMentionedJID(msg) != nil {
+				cmdAddGroupBlacklist(chat, msg, sender, args)
+			} else {
+				cmdShowGroupBlacklist(chat)
+			}
+			return
+		case "tirardalistagp":
+			cmdRemoveGroupBlacklist(chat, msg, sender, args)
 			return
 		case "advertir", "adverter":
 			cmdWarn(chat, msg, sender, args)
@@ -417,58 +697,79 @@ func handleMessage(msg *events.Message) {
 			cmdCheckWarnings(chat, msg)
 			return
 		case "removewarnings", "rm_adv":
-			cmdRemoveWarning(chat, msg)
+			cmdRemoveWarning(chat, msg, sender)
 			return
 		case "clearwarnings", "limpar_adv":
-			cmdClearWarnings(chat)
+			cmdClearWarnings(chat, sender)
 			return
 		case "advertidos", "lista_adv":
 			cmdListWarnings(chat)
 			return
 		case "mute":
-			cmdMute(chat, msg)
+			cmdMute(chat, msg, sender)
 			return
 		case "desmute":
-			cmdUnmute(chat, msg)
+			cmdUnmute(chat, msg, sender)
 			return
 		case "promover":
-			cmdPromote(chat, msg)
+			cmdPromote(chat, msg, sender)
 			return
 		case "rebaixar":
-			cmdDemote(chat, msg)
+			cmdDemote(chat, msg, sender)
 			return
 		case "bemvindo":
-			cmdToggleWelcome(chat)
+			cmdToggleWelcome(chat, sender)
 			return
 		case "antilink":
-			cmdToggleAntilink(chat)
+			cmdToggleAntilink(chat, sender)
 			return
 		case "antifake":
-			cmdToggleAntifake(chat)
+			cmdToggleAntifake(chat, sender)
 			return
 		case "antipalavra":
-			cmdToggleAntiPalavrao(chat)
+			cmdToggleAntiPalavrao(chat, sender)
 			return
 		case "autosticker":
-			cmdToggleAutoSticker(chat)
+			cmdToggleAutoSticker(chat, sender)
 			return
 		case "autodl":
-			cmdToggleAutoDL(chat)
+			cmdToggleAutoDL(chat, sender)
 			return
 		case "so_adm":
-			cmdToggleOnlyAdmin(chat)
+			cmdToggleOnlyAdmin(chat, sender)
+			return
+		case "reacaomod":
+			cmdReactionActions(chat, sender, args)
+			return
+		case "antidelete", "antiapagar":
+			cmdAntiDelete(chat, sender, args)
+			return
+		case "antimarcar":
+			cmdMentionRule(chat, sender, args)
+			return
+		case "antitrava":
+			cmdAntiTrava(chat, sender, args)
+			return
+		case "antimidia":
+			cmdMediaFilter(chat, sender, args)
+			return
+		case "avisoadm":
+			cmdToggleAdminNotices(chat, sender)
+			return
+		case "protegeradm":
+			cmdProtectAdmin(chat, msg, sender)
 			return
 		case "fechargp", "colloportus":
-			cmdCloseGroup(chat)
+			cmdCloseGroup(chat, sender)
 			return
 		case "abrirgp", "alohomora":
-			cmdOpenGroup(chat)
+			cmdOpenGroup(chat, sender)
 			return
 		case "nomegp":
-			cmdSetGroupName(chat, args)
+			cmdSetGroupName(chat, sender, args)
 			return
 		case "descgp":
-			cmdSetGroupDesc(chat, args)
+			cmdSetGroupDesc(chat, sender, args)
 			return
 		case "linkgp":
 			cmdGetGroupLink(chat)
@@ -482,10 +783,10 @@ func handleMessage(msg *events.Message) {
 		case "aceitar", "aceitarmembro":
 			return
 		case "banghost":
-			cmdBanGhost(chat)
+			cmdBanGhost(chat, sender)
 			return
 		case "banfakes", "banfake":
-			cmdBanFakes(chat)
+			cmdBanFakes(chat, sender)
 			return
 		case "inativos":
 			return
@@ -496,10 +797,10 @@ func handleMessage(msg *events.Message) {
 			cmdGroupStatus(chat)
 			return
 		case "addpalavra", "add_palavra":
-			cmdAddBadWord(chat, args)
+			cmdAddBadWord(chat, sender, args)
 			return
 		case "delpalavra", "rm_palavra":
-			cmdDelBadWord(chat, args)
+			cmdDelBadWord(chat, sender, args)
 			return
 		case "listapalavrao":
 			cmdListBadWords(chat)
@@ -514,6 +815,7 @@ func handleMessage(msg *events.Message) {
 			cmdDelNote(chat, args)
 			return
 		case "deletar":
+			cmdDeleteMessage(chat, msg, sender)
 			return
 		case "grupoinfo", "gpinfo":
 			cmdGroupInfo(chat)
@@ -522,13 +824,67 @@ func handleMessage(msg *events.Message) {
 			cmdListAdmins(chat)
 			return
 		case "roleta":
-			cmdRoleta(chat)
+			cmdRoleta(chat, sender)
+			return
+		case "logs":
+			cmdLogs(chat, msg, args, false)
+			return
+		case "cargos":
+			cmdListRoles(chat)
+			return
+		case "permcmd":
+			cmdSetCommandRole(chat, sender, args)
+			return
+		case "setmsg":
+			cmdSetGroupMessage(chat, sender, args)
+			return
+		case "setmsgban":
+			cmdSetGroupMessage(chat, sender, "ban "+args)
+			return
+		case "reports", "denuncias":
+			cmdListReports(chat)
 			return
-		case "setmsg", "setmsgban":
+		case "resolver":
+			cmdResolveReport(chat, sender, args)
+			return
+		case "apelacoes":
+			cmdListAppeals(chat, false)
+			return
+		case "aceitarapelo", "negarapelo":
+			cmdResolveAppeal(chat, sender, cmd == "aceitarapelo", args, false)
+			return
+		case "travarconfig":
+			cmdLockSettings(chat, sender, args)
+			return
+		case "destravarconfig":
+			cmdUnlockSettings(chat, sender)
+			return
+		case "sethorario":
+			cmdSetSchedule(chat, sender, args)
+			return
+		case "horariomsg":
+			cmdScheduleMessage(chat, sender, args)
+			return
+		case "slowmode":
+			cmdSlowMode(chat, sender, args)
+			return
+		case "votekickcfg":
+			cmdVoteKickConfig(chat, sender, args)
+			return
+		case "relatoriosgp":
+			cmdSetReportGroup(chat, sender, args)
+			return
+		case "vermsg":
+			cmdShowGroupMessages(chat, args)
+			return
+		case "resetmsg":
+			cmdResetGroupMessage(chat, sender, args)
 			return
 		case "block", "bloquearcmd":
+			cmdBlockCommand(chat, sender, args)
 			return
 		case "liberarcmd":
+			cmdUnblockCommand(chat, sender, args)
 			return
 		}
 	}
@@ -593,6 +949,22 @@ func handleMessage(msg *events.Message) {
 		cmdRegras(chat)
 	case "bug", "sugestao":
 		cmdBugReport(chat, sender, args)
+	case "report", "denunciar":
+		if isGroup {
+			cmdReport(chat, msg, sender, args)
+		}
+	case "apelar":
+		if !isGroup {
+			cmdAppeal(chat, sender, args)
+		}
+	case "horario":
+		if isGroup {
+			cmdShowSchedule(chat)
+		}
+	case "votekick", "votarban":
+		if isGroup {
+			cmdVoteKick(chat, msg, sender)
+		}
 	}
 }
 
@@ -600,23 +972,161 @@ func handleMessage(msg *events.Message) {
 // Message Helpers
 // ============================================================
 
-func getMessageText(msg *events.Message) string {
-	if msg.Message == nil {
-		return ""
-	}
-	if msg.Message.GetConversation() != "" {
-		return msg.Message.GetConversation()
-	}
-	if msg.Message.GetExtendedTextMessage() != nil {
-		return msg.Message.GetExtendedTextMessage().GetText()
+// Tipos de mensagem identificados por extractMessage
+const (
+	KindText     = "texto"
+	KindImage    = "imagem"
+	KindVideo    = "video"
+	KindAudio    = "audio"
+	KindDocument = "documento"
+	KindSticker  = "figurinha"
+	KindContact  = "contato"
+	KindLocation = "localizacao"
+	KindPoll     = "enquete"
+	KindButton   = "botao"
+	KindList     = "lista"
+	KindOther    = "outro"
+)
+
+// messageContent e o resultado de extractMessage: o texto que o usuario escreveu
+// (legenda, nome de enquete, comentario de localizacao...) e o tipo da mensagem.
+type messageContent struct {
+	Text          string
+	Kind          string
+	ViewOnce      bool
+	StatusMention bool // mencao do grupo em um status: conta como marcar todos
+	Context       *waE2E.ContextInfo
+	Message       *waE2E.Message // mensagem sem os envelopes
+}
+
+// unwrapMessage remove os envelopes (visualizacao unica, temporaria, enviada de
+// outro aparelho, documento com legenda, edicao) ate chegar ao conteudo.
+func unwrapMessage(m *waE2E.Message) (*waE2E.Message, bool) {
+	viewOnce := false
+	for i := 0; i < 5 && m != nil; i++ {
+		var inner *waE2E.Message
+		switch {
+		case m.GetDeviceSentMessage().GetMessage() != nil:
+			inner = m.GetDeviceSentMessage().GetMessage()
+		case m.GetEphemeralMessage().GetMessage() != nil:
+			inner = m.GetEphemeralMessage().GetMessage()
+		case m.GetViewOnceMessage().GetMessage() != nil:
+			inner, viewOnce = m.GetViewOnceMessage().GetMessage(), true
+		case m.GetViewOnceMessageV2().GetMessage() != nil:
+			inner, viewOnce = m.GetViewOnceMessageV2().GetMessage(), true
+		case m.GetViewOnceMessageV2Extension().GetMessage() != nil:
+			inner, viewOnce = m.GetViewOnceMessageV2Extension().GetMessage(), true
+		case m.GetDocumentWithCaptionMessage().GetMessage() != nil:
+			inner = m.GetDocumentWithCaptionMessage().GetMessage()
+		case m.GetEditedMessage().GetMessage() != nil:
+			inner = m.GetEditedMessage().GetMessage()
+		}
+		if inner == nil {
+			break
+		}
+		m = inner
 	}
-	if msg.Message.GetImageMessage() != nil {
-		return msg.Message.GetImageMessage().GetCaption()
+	return m, viewOnce
+}
+
+func joinNonEmpty(parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
 	}
-	if msg.Message.GetVideoMessage() != nil {
-		return msg.Message.GetVideoMessage().GetCaption()
+	return strings.Join(out, "\n")
+}
+
+// extractMessage e o ponto unico de leitura do conteudo: usado pela moderacao e
+// pelos comandos, para que nenhum tipo de mensagem passe sem ser lido.
+func extractMessage(raw *waE2E.Message) messageContent {
+	m, viewOnce := unwrapMessage(raw)
+	statusMention := false
+	if sm := m.GetGroupStatusMentionMessage(); sm != nil {
+		statusMention = true
+		var innerViewOnce bool
+		m, innerViewOnce = unwrapMessage(sm.GetMessage())
+		viewOnce = viewOnce || innerViewOnce
+	}
+	c := messageContent{Kind: KindOther, ViewOnce: viewOnce, StatusMention: statusMention, Message: m}
+	if m == nil {
+		return c
+	}
+	switch {
+	case m.GetConversation() != "":
+		c.Kind, c.Text = KindText, m.GetConversation()
+	case m.GetExtendedTextMessage() != nil:
+		x := m.GetExtendedTextMessage()
+		c.Kind, c.Text, c.Context = KindText, x.GetText(), x.GetContextInfo()
+	case m.GetImageMessage() != nil:
+		x := m.GetImageMessage()
+		c.Kind, c.Text, c.Context = KindImage, x.GetCaption(), x.GetContextInfo()
+	case m.GetVideoMessage() != nil:
+		x := m.GetVideoMessage()
+		c.Kind, c.Text, c.Context = KindVideo, x.GetCaption(), x.GetContextInfo()
+	case m.GetAudioMessage() != nil:
+		c.Kind, c.Context = KindAudio, m.GetAudioMessage().GetContextInfo()
+	case m.GetDocumentMessage() != nil:
+		x := m.GetDocumentMessage()
+		c.Kind, c.Text, c.Context = KindDocument, x.GetCaption(), x.GetContextInfo()
+	case m.GetStickerMessage() != nil:
+		c.Kind, c.Context = KindSticker, m.GetStickerMessage().GetContextInfo()
+	case m.GetContactMessage() != nil:
+		x := m.GetContactMessage()
+		c.Kind, c.Text, c.Context = KindContact, x.GetDisplayName(), x.GetContextInfo()
+	case m.GetContactsArrayMessage() != nil:
+		x := m.GetContactsArrayMessage()
+		names := []string{x.GetDisplayName()}
+		for _, ct := range x.GetContacts() {
+			names = append(names, ct.GetDisplayName())
+		}
+		c.Kind, c.Text, c.Context = KindContact, joinNonEmpty(names...), x.GetContextInfo()
+	case m.GetLocationMessage() != nil:
+		x := m.GetLocationMessage()
+		c.Kind, c.Text, c.Context = KindLocation, joinNonEmpty(x.GetName(), x.GetAddress(), x.GetComment(), x.GetURL()), x.GetContextInfo()
+	case m.GetLiveLocationMessage() != nil:
+		x := m.GetLiveLocationMessage()
+		c.Kind, c.Text, c.Context = KindLocation, x.GetCaption(), x.GetContextInfo()
+	case m.GetPollCreationMessage() != nil || m.GetPollCreationMessageV2() != nil || m.GetPollCreationMessageV3() != nil:
+		x := m.GetPollCreationMessage()
+		if x == nil {
+			x = m.GetPollCreationMessageV2()
+		}
+		if x == nil {
+			x = m.GetPollCreationMessageV3()
+		}
+		parts := []string{x.GetName()}
+		for _, opt := range x.GetOptions() {
+			parts = append(parts, opt.GetOptionName())
+		}
+		c.Kind, c.Text, c.Context = KindPoll, joinNonEmpty(parts...), x.GetContextInfo()
+	case m.GetButtonsResponseMessage() != nil:
+		x := m.GetButtonsResponseMessage()
+		c.Kind, c.Text, c.Context = KindButton, x.GetSelectedDisplayText(), x.GetContextInfo()
+		if c.Text == "" {
+			c.Text = x.GetSelectedButtonID()
+		}
+	case m.GetTemplateButtonReplyMessage() != nil:
+		x := m.GetTemplateButtonReplyMessage()
+		c.Kind, c.Text, c.Context = KindButton, x.GetSelectedDisplayText(), x.GetContextInfo()
+		if c.Text == "" {
+			c.Text = x.GetSelectedID()
+		}
+	case m.GetListResponseMessage() != nil:
+		x := m.GetListResponseMessage()
+		c.Kind, c.Text, c.Context = KindList, x.GetTitle(), x.GetContextInfo()
+		if c.Text == "" {
+			c.Text = x.GetSingleSelectReply().GetSelectedRowID()
+		}
 	}
-	return ""
+	return c
+}
+
+// getContextInfo retorna o ContextInfo (mencoes e mensagem respondida) da mensagem.
+func getContextInfo(msg *events.Message) *waE2E.ContextInfo {
+	return extractMessage(msg.Message).Context
 }
 
 func sendText(chat types.JID, text string) {
@@ -632,6 +1142,11 @@ func sendText(chat types.JID, text string) {
 }
 
 func sendMention(chat types.JID, text string, mentions []string) {
+	sendMentionGetID(chat, text, mentions)
+}
+
+// sendMentionGetID envia a mensagem com mencoes e retorna o ID dela ("" em caso de erro).
+func sendMentionGetID(chat types.JID, text string, mentions []string) types.MessageID {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 	jids := make([]string, len(mentions))
@@ -646,10 +1161,12 @@ func sendMention(chat types.JID, text string, mentions []string) {
 			},
 		},
 	}
-	_, err := client.SendMessage(ctx, chat, msg)
+	resp, err := client.SendMessage(ctx, chat, msg)
 	if err != nil {
 		fmt.Printf("[ERRO] Enviar mention: %v\n", err)
+		return ""
 	}
+	return resp.ID
 }
 
 func isOwnerNumber(number string) bool {
@@ -709,10 +1226,28 @@ func isBotAdmin(chat types.JID) bool {
 	return isGroupAdmin(chat, *client.Store.ID)
 }
 
-func removeMember(chat types.JID, user types.JID) {
+// deleteMessage apaga a mensagem de outro membro para todos (exige bot admin).
+func deleteMessage(chat types.JID, sender types.JID, id types.MessageID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err := client.SendMessage(ctx, chat, client.BuildRevoke(chat, sender, id))
+	if err != nil {
+		fmt.Printf("[ERRO] Apagar mensagem: %v\n", err)
+	}
+}
+
+// isModerationExempt indica quem nao sofre filtros automaticos: dono, admins e membros com cargo.
+func isModerationExempt(chat types.JID, user types.JID) bool {
+	if isOwnerNumber(user.User) || getRole(chat.String(), user.User) != "membro" {
+		return true
+	}
+	return isGroupAdmin(chat, user)
+}
+
+func removeMember(chat types.JID, user types.JID) error {
 	if !isBotAdmin(chat) {
 		sendText(chat, "*[OdinBOT]* Preciso ser admin para remover membros.")
-		return
+		return fmt.Errorf("bot nao e admin do grupo")
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -720,6 +1255,69 @@ func removeMember(chat types.JID, user types.JID) {
 	if err != nil {
 		fmt.Printf("[ERRO] Remover membro: %v\n", err)
 	}
+	return err
+}
+
+func addMember(chat types.JID, user types.JID) error {
+	if !isBotAdmin(chat) {
+		return fmt.Errorf("bot nao e admin do grupo")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err := client.UpdateGroupParticipants(ctx, chat, []types.JID{user}, whatsmeow.ParticipantChangeAdd)
+	return err
+}
+
+// moderateGroupMessage aplica os filtros de conteudo do grupo (anti-link e
+// anti-palavrao). Retorna true se alguma acao foi tomada.
+func moderateGroupMessage(chat types.JID, sender types.JID, text string) bool {
+	groupJID := chat.String()
+	cfg := getGroupConfig(groupJID)
+	if isOwnerNumber(sender.User) || isGroupAdmin(chat, sender) {
+		return false
+	}
+
+	// Anti-link
+	if cfg.Antilink && containsLink(text) {
+		removeMember(chat, sender)
+		logAction(groupJID, BotName, sender.User, "antilink", "Enviou link", SourceAuto)
+		sendGroupTemplate(chat, "antilink", sender.User, map[string]string{"reason": "enviar link"})
+		return true
+	}
+
+	// Anti-palavrao
+	if cfg.AntiPalavrao && containsBadWord(groupJID, text) {
+		addWarningAuto(groupJID, sender, "Palavra proibida detectada")
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s cuidado com as palavras! Advertencia aplicada.", sender.User))
+		return true
+	}
+	return false
+}
+
+// getEditedMessage retorna o novo conteudo e o ID da mensagem original quando a
+// mensagem e uma edicao (protocolo MESSAGE_EDIT, com ou sem o envelope EditedMessage).
+func getEditedMessage(m *waE2E.Message) (*waE2E.Message, types.MessageID) {
+	m, _ = unwrapMessage(m)
+	p := m.GetProtocolMessage()
+	if p == nil || p.GetType() != waE2E.ProtocolMessage_MESSAGE_EDIT {
+		return nil, ""
+	}
+	return p.GetEditedMessage(), p.GetKey().GetID()
+}
+
+// handleEditedMessage passa o texto editado pelos mesmos filtros de uma mensagem
+// nova: sem isso bastaria mandar um texto limpo e depois editar um link nele.
+// Edicoes nao executam comandos.
+func handleEditedMessage(chat types.JID, sender types.JID, edited *waE2E.Message, originalID types.MessageID) {
+	content := extractMessage(edited)
+	if checkTrava(chat, sender, originalID, content) {
+		return
+	}
+	text := content.Text
+	if text == "" {
+		return
+	}
+	moderateGroupMessage(chat, sender, text)
 }
 
 func containsLink(text string) bool {
@@ -755,21 +1353,13 @@ func containsBadWord(groupJID, text string) bool {
 	return false
 }
 
-func isBlacklisted(number string) bool {
-	botData.mu.RLock()
-	defer botData.mu.RUnlock()
-	_, ok := botData.Blacklist[number]
+func isBlacklisted(number string, groupJID string) bool {
+	_, ok := findBlacklistEntry(number, groupJID)
 	return ok
 }
 
 func getMentionedJID(msg *events.Message) *types.JID {
-	if msg.Message == nil {
-		return nil
-	}
-	var ctx *waE2E.ContextInfo
-	if msg.Message.GetExtendedTextMessage() != nil {
-		ctx = msg.Message.GetExtendedTextMessage().GetContextInfo()
-	}
+	ctx := getContextInfo(msg)
 	if ctx == nil {
 		return nil
 	}
@@ -779,1032 +1369,5730 @@ func getMentionedJID(msg *events.Message) *types.JID {
 			return &jid
 		}
 	}
-	if ctx.GetParticipant() != "" {
-		jid, err := types.ParseJID(ctx.GetParticipant())
-		if err == nil {
-			return &jid
-		}
+	if quoted, ok := getQuotedMessage(msg); ok {
+		return &quoted.Sender
 	}
 	return nil
 }
 
 // ============================================================
-// Group Events
+// Roles & Permissions
 // ============================================================
 
-func handleJoinedGroup(evt *events.JoinedGroup) {
-	sendText(evt.JID, fmt.Sprintf(
-		"*%s conectado!*\n\nOla! Sou o %s, bot do %s.\nUse *%smenu* para ver meus comandos.\nDono: %s",
-		BotName, BotName, OwnerName, DefaultPrefix, OwnerName,
-	))
+// Permissoes concedidas pelos cargos do #cargo
+const (
+	PermWarn   = "advertir"
+	PermMute   = "mutar"
+	PermBan    = "banir"
+	PermConfig = "config"
+	PermManage = "gerenciar"
+)
+
+var roleLevels = map[string]int{
+	"membro":        0,
+	"auxiliar":      1,
+	"moderador":     2,
+	"administrador": 3,
 }
 
-// ============================================================
-// AFK System
-// ============================================================
+var rolePermissions = map[string][]string{
+	"auxiliar":      {PermWarn, PermMute},
+	"moderador":     {PermWarn, PermMute, PermBan, PermConfig},
+	"administrador": {PermWarn, PermMute, PermBan, PermConfig, PermManage},
+}
 
-func cmdSetAfk(chat types.JID, sender types.JID, reason string) {
-	if reason == "" {
-		reason = "Sem motivo informado"
-	}
-	botData.mu.Lock()
-	botData.AfkUsers[sender.User] = reason
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s agora esta AFK: %s", sender.User, reason))
+// Apelidos de comandos -> nome canonico (usado em permissoes e overrides)
+var commandAliases = map[string]string{
+	"add_contrat":     "aluguel",
+	"exitgp":          "sairgp",
+	"adverter":        "advertir",
+	"ver_adv":         "checkwarnings",
+	"rm_adv":          "removewarnings",
+	"limpar_adv":      "clearwarnings",
+	"lista_adv":       "advertidos",
+	"colloportus":     "fechargp",
+	"alohomora":       "abrirgp",
+	"marcar":          "tagall",
+	"hidetag":         "totag",
+	"aceitarmembro":   "aceitar",
+	"banfake":         "banfakes",
+	"ativacoes":       "status",
+	"add_palavra":     "addpalavra",
+	"rm_palavra":      "delpalavra",
+	"anotacoes":       "anotacao",
+	"rmnota":          "tirar_nota",
+	"gpinfo":          "grupoinfo",
+	"setmsgban":       "setmsg",
+	"blgp":            "listanegragp",
+	"antiapagar":      "antidelete",
+	"bloquearcmd":     "block",
+	"infobot":         "info",
+	"criador":         "dono",
+	"s":               "sticker",
+	"fig":             "sticker",
+	"me":              "perfil",
+	"ausente":         "afk",
+	"statusafk":       "listarafk",
+	"rankativos":      "rankativo",
+	"conselhobiblico": "conselho",
+	"calcular":        "calculadora",
+	"ajuda":           "help",
+	"sugestao":        "bug",
+	"denunciar":       "report",
+	"denuncias":       "reports",
+	"votarban":        "votekick",
 }
 
-func cmdRemoveAfk(chat types.JID, sender types.JID) {
-	botData.mu.Lock()
-	delete(botData.AfkUsers, sender.User)
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s voltou da ausencia!", sender.User))
+// Permissao necessaria por comando; comandos ausentes sao livres
+var commandPermissions = map[string]string{
+	"advertir":        PermWarn,
+	"checkwarnings":   PermWarn,
+	"removewarnings":  PermWarn,
+	"advertidos":      PermWarn,
+	"admins":          PermWarn,
+	"mute":            PermMute,
+	"desmute":         PermMute,
+	"ban":             PermBan,
+	"tempban":         PermBan,
+	"listanegragp":    PermBan,
+	"tirardalistagp":  PermBan,
+	"clearwarnings":   PermBan,
+	"banghost":        PermBan,
+	"banfakes":        PermBan,
+	"roleta":          PermBan,
+	"bemvindo":        PermConfig,
+	"antilink":        PermConfig,
+	"antifake":        PermConfig,
+	"antipalavra":     PermConfig,
+	"autosticker":     PermConfig,
+	"autodl":          PermConfig,
+	"so_adm":          PermConfig,
+	"avisoadm":        PermConfig,
+	"antimidia":       PermConfig,
+	"antitrava":       PermConfig,
+	"antimarcar":      PermConfig,
+	"antidelete":      PermConfig,
+	"reacaomod":       PermConfig,
+	"protegeradm":     PermManage,
+	"addpalavra":      PermConfig,
+	"delpalavra":      PermConfig,
+	"listapalavrao":   PermConfig,
+	"promover":        PermManage,
+	"rebaixar":        PermManage,
+	"fechargp":        PermManage,
+	"abrirgp":         PermManage,
+	"nomegp":          PermManage,
+	"descgp":          PermManage,
+	"linkgp":          PermManage,
+	"tagall":          PermManage,
+	"totag":           PermManage,
+	"aceitar":         PermManage,
+	"inativos":        PermManage,
+	"sorteio":         PermManage,
+	"status":          PermManage,
+	"anotar":          PermManage,
+	"anotacao":        PermManage,
+	"tirar_nota":      PermManage,
+	"deletar":         PermManage,
+	"grupoinfo":       PermManage,
+	"logs":            PermManage,
+	"cargos":          PermManage,
+	"permcmd":         PermManage,
+	"setmsg":          PermManage,
+	"vermsg":          PermManage,
+	"reports":         PermWarn,
+	"apelacoes":       PermBan,
+	"aceitarapelo":    PermBan,
+	"negarapelo":      PermBan,
+	"resolver":        PermWarn,
+	"relatoriosgp":    PermManage,
+	"votekickcfg":     PermConfig,
+	"slowmode":        PermConfig,
+	"sethorario":      PermManage,
+	"travarconfig":    PermManage,
+	"destravarconfig": PermManage,
+	"horariomsg":      PermManage,
+	"resetmsg":        PermManage,
+	"block":           PermManage,
+	"liberarcmd":      PermManage,
 }
 
-func cmdListAfk(chat types.JID) {
+func canonicalCommand(cmd string) string {
+	if c, ok := commandAliases[cmd]; ok {
+		return c
+	}
+	return cmd
+}
+
+func getRole(groupJID, user string) string {
 	botData.mu.RLock()
 	defer botData.mu.RUnlock()
-	if len(botData.AfkUsers) == 0 {
-		sendText(chat, "*[OdinBOT]* Nenhum usuario AFK.")
-		return
+	if role, ok := botData.Roles[groupJID][user]; ok {
+		return role
 	}
-	msg := "*[OdinBOT] Usuarios AFK:*\n\n"
-	for user, reason := range botData.AfkUsers {
-		msg += fmt.Sprintf("- @%s: %s\n", user, reason)
+	return "membro"
+}
+
+// roleLevel retorna o nivel efetivo do usuario: admin do WhatsApp conta como administrador.
+func roleLevel(chat types.JID, user types.JID) int {
+	if isGroupAdmin(chat, user) {
+		return roleLevels["administrador"]
 	}
-	sendText(chat, msg)
+	return roleLevels[getRole(chat.String(), user.User)]
 }
 
-func checkAfk(chat types.JID, sender types.JID, _ string) {
+// outranks indica se issuer pode punir target: o nivel do alvo tem que ser menor.
+// O dono pode tudo e nunca pode ser punido.
+func outranks(chat types.JID, issuer types.JID, target types.JID) bool {
+	if isOwnerNumber(issuer.User) {
+		return true
+	}
+	if isOwnerNumber(target.User) {
+		return false
+	}
+	return roleLevel(chat, target) < roleLevel(chat, issuer)
+}
+
+const outrankedMsg = "*[OdinBOT]* Voce nao pode punir alguem com cargo igual ou maior que o seu."
+
+func hasPermission(chat types.JID, user types.JID, perm string) bool {
+	for _, p := range rolePermissions[getRole(chat.String(), user.User)] {
+		if p == perm {
+			return true
+		}
+	}
+	return isGroupAdmin(chat, user)
+}
+
+func hasAnyPermission(chat types.JID, user types.JID) bool {
+	return len(rolePermissions[getRole(chat.String(), user.User)]) > 0 || isGroupAdmin(chat, user)
+}
+
+func canRunCommand(chat types.JID, user types.JID, cmd string) bool {
+	cmd = canonicalCommand(cmd)
+	cfg := getGroupConfig(chat.String())
 	botData.mu.RLock()
-	reason, ok := botData.AfkUsers[sender.User]
+	minRole, overridden := cfg.CmdRoles[cmd]
 	botData.mu.RUnlock()
-	if ok {
-		botData.mu.Lock()
-		delete(botData.AfkUsers, sender.User)
-		botData.mu.Unlock()
-		saveBotData()
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s voltou! (estava AFK: %s)", sender.User, reason))
+	if overridden {
+		return roleLevel(chat, user) >= roleLevels[minRole]
+	}
+	perm, ok := commandPermissions[cmd]
+	if !ok {
+		return true
 	}
+	return hasPermission(chat, user, perm)
 }
 
-// ============================================================
-// Owner Commands
-// ============================================================
+func cmdListRoles(chat types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.RLock()
+	roles := botData.Roles[gJID]
+	msg := "*[OdinBOT] Cargos do Grupo:*\n\n"
+	var mentions []string
+	for user, role := range roles {
+		if role == "membro" {
+			continue
+		}
+		msg += fmt.Sprintf("- @%s: %s\n", user, role)
+		mentions = append(mentions, user)
+	}
+	if len(mentions) == 0 {
+		msg += "Nenhum cargo definido.\n"
+	}
+	if len(cfg.CmdRoles) > 0 {
+		msg += "\n*Permissoes personalizadas:*\n"
+		for cmd, role := range cfg.CmdRoles {
+			msg += fmt.Sprintf("- %s: %s+\n", cmd, role)
+		}
+	}
+	botData.mu.RUnlock()
+	msg += "\n*Padrao:* auxiliar = advertir/mutar | moderador = + banir/filtros | administrador = tudo"
+	sendMention(chat, msg, mentions)
+}
 
-func cmdAluguel(chat types.JID, args string) {
-	parts := strings.Split(args, "|")
-	if len(parts) < 5 {
-		sendText(chat, "*[OdinBOT]* Uso: #aluguel grupo_jid|nome_grupo|dono_num|plano|valor\nEx: #aluguel 120363...@g.us|MeuGrupo|5511999|Mensal|50")
+func cmdSetCommandRole(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(strings.ToLower(args))
+	if len(parts) < 2 {
+		sendText(chat, "*[OdinBOT]* Uso: #permcmd <comando> <membro/auxiliar/moderador/administrador/padrao>")
 		return
 	}
-	val := 0.0
-	fmt.Sscanf(strings.TrimSpace(parts[4]), "%f", &val)
-	ownerName := ""
-	if len(parts) > 5 {
-		ownerName = strings.TrimSpace(parts[5])
+	cmd := canonicalCommand(strings.TrimPrefix(parts[0], getPrefix(chat.String())))
+	role := parts[1]
+	if _, ok := roleLevels[role]; !ok && role != "padrao" {
+		sendText(chat, "*[OdinBOT]* Cargos validos: membro, auxiliar, moderador, administrador, padrao")
+		return
 	}
-	rental := Rental{
-		GroupJID:  strings.TrimSpace(parts[0]),
-		GroupName: strings.TrimSpace(parts[1]),
-		OwnerNum:  strings.TrimSpace(parts[2]),
-		OwnerName: ownerName,
-		Plan:      strings.TrimSpace(parts[3]),
-		Value:     val,
-		StartDate: time.Now().Format("2006-01-02"),
-		EndDate:   calcEndDate(strings.TrimSpace(parts[3])),
-		Active:    true,
+	if cmd == "permcmd" {
+		sendText(chat, "*[OdinBOT]* Esse comando nao pode ser alterado.")
+		return
 	}
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
 	botData.mu.Lock()
-	botData.Rentals = append(botData.Rentals, rental)
+	if role == "padrao" {
+		delete(cfg.CmdRoles, cmd)
+	} else {
+		if cfg.CmdRoles == nil {
+			cfg.CmdRoles = make(map[string]string)
+		}
+		cfg.CmdRoles[cmd] = role
+	}
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Aluguel registrado!\nGrupo: %s\nPlano: %s\nValor: R$%.2f\nVencimento: %s",
-		rental.GroupName, rental.Plan, rental.Value, rental.EndDate))
+	logAction(gJID, sender.User, "", "permcmd", cmd+" "+role, SourceCommand)
+	if role == "padrao" {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Permissao de %s restaurada para o padrao.", cmd))
+	} else {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %s agora exige cargo %s ou superior.", cmd, role))
+	}
 }
 
-func cmdVerificarAluguel(chat types.JID) {
-	botData.mu.RLock()
-	defer botData.mu.RUnlock()
-	if len(botData.Rentals) == 0 {
-		sendText(chat, "*[OdinBOT]* Nenhum aluguel registrado.")
-		return
+// ============================================================
+// Command Blocking
+// ============================================================
+
+// Categoria de cada comando (mesmas secoes do #menu). Comandos com
+// permissao em commandPermissions pertencem a "adm".
+var commandCategories = map[string]string{
+	"menu":              "geral",
+	"ping":              "geral",
+	"info":              "geral",
+	"dono":              "geral",
+	"help":              "geral",
+	"alugar":            "geral",
+	"bug":               "geral",
+	"regras":            "grupo",
+	"report":            "grupo",
+	"apelar":            "geral",
+	"votekick":          "grupo",
+	"horario":           "grupo",
+	"sticker":           "figurinhas",
+	"toimg":             "figurinhas",
+	"traduzir":          "utilidades",
+	"clima":             "utilidades",
+	"signo":             "utilidades",
+	"calculadora":       "utilidades",
+	"sn":                "utilidades",
+	"sorte":             "utilidades",
+	"cantadas":          "utilidades",
+	"fatos":             "utilidades",
+	"conselho":          "utilidades",
+	"admins":            "grupo",
+	"perfil":            "grupo",
+	"rankativo":         "grupo",
+	"afk":               "grupo",
+	"ativo":             "grupo",
+	"listarafk":         "grupo",
+	"ppt":               "jogos",
+	"chance":            "jogos",
+	"moedas":            "jogos",
+	"dado":              "jogos",
+	"simi":              "jogos",
+	"roleta":            "jogos",
+	"sorteio":           "jogos",
+	"aluguel":           "dono",
+	"verificar_aluguel": "dono",
+	"bcaluguel":         "dono",
+	"bc":                "dono",
+	"join":              "dono",
+	"sairgp":            "dono",
+	"listanegra":        "dono",
+	"tirardalista":      "dono",
+	"nuke":              "dono",
+	"grupos":            "dono",
+	"cargo":             "dono",
+	"manutencao":        "dono",
+	"exportlista":       "dono",
+	"antiligar":         "dono",
+	"cachemsg":          "dono",
+	"spamcruzado":       "dono",
+	"varrerlista":       "dono",
+	"importlista":       "dono",
+	"listafonte":        "dono",
+}
+
+// Comandos que nunca podem ser bloqueados (senao nao ha como desbloquear)
+var unblockableCommands = map[string]bool{
+	"block":      true,
+	"liberarcmd": true,
+	"manutencao": true,
+}
+
+func commandCategory(cmd string) string {
+	if cat, ok := commandCategories[cmd]; ok {
+		return cat
 	}
-	msg := "*[OdinBOT] Alugueis:*\n\n"
-	for i, r := range botData.Rentals {
-		status := "Ativo"
-		if !r.Active {
-			status = "Expirado"
+	if _, ok := commandPermissions[cmd]; ok {
+		return "adm"
+	}
+	return ""
+}
+
+func isCategory(name string) bool {
+	for _, cat := range commandCategories {
+		if cat == name {
+			return true
 		}
-		msg += fmt.Sprintf("%d. %s\n   Dono: %s | Plano: %s | R$%.2f\n   Venc: %s | Status: %s\n\n",
-			i+1, r.GroupName, r.OwnerNum, r.Plan, r.Value, r.EndDate, status)
 	}
-	sendText(chat, msg)
+	return name == "adm"
 }
 
-func cmdBroadcastAluguel(chat types.JID, args string) {
+// isCommandDisabled informa se o comando (ou sua categoria) esta bloqueado.
+// global = true quando o bloqueio vem da lista de manutencao do dono.
+func isCommandDisabled(groupJID string, isGroup bool, cmd string) (disabled bool, global bool) {
+	cmd = canonicalCommand(cmd)
+	if unblockableCommands[cmd] {
+		return false, false
+	}
+	cat := commandCategory(cmd)
+	matches := func(list []string) bool {
+		for _, d := range list {
+			if d == cmd || (cat != "" && d == cat) {
+				return true
+			}
+		}
+		return false
+	}
 	botData.mu.RLock()
 	defer botData.mu.RUnlock()
-	count := 0
-	for _, r := range botData.Rentals {
-		if r.Active {
-			jid, err := types.ParseJID(r.GroupJID)
-			if err == nil {
-				sendText(jid, fmt.Sprintf("*[OdinBOT - Aviso de Aluguel]*\n\n%s", args))
-				count++
-			}
+	if matches(botData.DisabledCmds) {
+		return true, true
+	}
+	if isGroup {
+		if cfg, ok := botData.Groups[groupJID]; ok && matches(cfg.DisabledCmds) {
+			return true, false
 		}
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Broadcast enviado para %d grupos alugados.", count))
+	return false, false
 }
 
-func cmdBroadcast(args string) {
-	if args == "" {
-		return
+// parseCommandTarget valida o argumento de #bloquearcmd/#liberarcmd/#manutencao
+func parseCommandTarget(chat types.JID, arg string) (string, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	arg = strings.TrimPrefix(arg, getPrefix(chat.String()))
+	if arg == "" {
+		return "", false
 	}
-	groups, err := client.GetJoinedGroups(context.Background())
-	if err != nil {
-		return
+	if isCategory(arg) {
+		return arg, true
 	}
-	for _, g := range groups {
-		sendText(g.JID, fmt.Sprintf("*[OdinBOT - Broadcast]*\n\n%s", args))
-		time.Sleep(500 * time.Millisecond)
+	cmd := canonicalCommand(arg)
+	if commandCategory(cmd) == "" || unblockableCommands[cmd] {
+		return "", false
 	}
+	return cmd, true
 }
 
-func cmdJoin(link string) {
-	link = strings.TrimSpace(link)
-	if !strings.Contains(link, "chat.whatsapp.com") {
-		return
+func formatDisabledList(list []string) string {
+	if len(list) == 0 {
+		return "Nenhum."
 	}
-	parts := strings.Split(link, "/")
-	code := parts[len(parts)-1]
-	_, err := client.JoinGroupWithLink(context.Background(), code)
-	if err != nil {
-		fmt.Printf("[ERRO] Entrar no grupo: %v\n", err)
+	out := ""
+	for _, d := range list {
+		if isCategory(d) {
+			out += fmt.Sprintf("- %s (categoria)\n", d)
+		} else {
+			out += fmt.Sprintf("- %s\n", d)
+		}
 	}
+	return out
 }
 
-func cmdLeaveGroup(chat types.JID) {
-	sendText(chat, "*[OdinBOT]* Saindo do grupo... Ate mais!")
-	time.Sleep(1 * time.Second)
-	_ = client.LeaveGroup(context.Background(), chat)
-}
-
-func cmdNuke(chat types.JID) {
-	if !isBotAdmin(chat) {
-		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+func cmdBlockCommand(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	if strings.TrimSpace(args) == "" {
+		botData.mu.RLock()
+		list := formatDisabledList(cfg.DisabledCmds)
+		botData.mu.RUnlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Comandos bloqueados:*\n\n%s\nUso: #bloquearcmd <comando|categoria>\nCategorias: geral, figurinhas, utilidades, grupo, jogos, adm", list))
 		return
 	}
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
+	target, ok := parseCommandTarget(chat, args)
+	if !ok {
+		sendText(chat, "*[OdinBOT]* Comando ou categoria invalido.")
 		return
 	}
-	var toRemove []types.JID
-	for _, p := range info.Participants {
-		if p.JID.User != client.Store.ID.User && !isOwnerNumber(p.JID.User) {
-			toRemove = append(toRemove, p.JID)
+	botData.mu.Lock()
+	for _, d := range cfg.DisabledCmds {
+		if d == target {
+			botData.mu.Unlock()
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* %s ja esta bloqueado.", target))
+			return
 		}
 	}
-	if len(toRemove) > 0 {
-		_, _ = client.UpdateGroupParticipants(context.Background(), chat, toRemove, whatsmeow.ParticipantChangeRemove)
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* Nuke executado. %d membros removidos.", len(toRemove)))
-	}
+	cfg.DisabledCmds = append(cfg.DisabledCmds, target)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "bloquearcmd", target, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s bloqueado neste grupo.", target))
 }
 
-func cmdListGroups(chat types.JID) {
-	groups, err := client.GetJoinedGroups(context.Background())
-	if err != nil {
-		sendText(chat, "*[OdinBOT]* Erro ao listar grupos.")
+func cmdUnblockCommand(chat types.JID, sender types.JID, args string) {
+	target, ok := parseCommandTarget(chat, args)
+	if !ok {
+		sendText(chat, "*[OdinBOT]* Uso: #liberarcmd <comando|categoria>")
 		return
 	}
-	msg := fmt.Sprintf("*[OdinBOT] Grupos (%d):*\n\n", len(groups))
-	for i, g := range groups {
-		msg += fmt.Sprintf("%d. %s\n   %s | %d membros\n\n", i+1, g.Name, g.JID.String(), len(g.Participants))
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	found := false
+	for i, d := range cfg.DisabledCmds {
+		if d == target {
+			cfg.DisabledCmds = append(cfg.DisabledCmds[:i], cfg.DisabledCmds[i+1:]...)
+			found = true
+			break
+		}
 	}
-	sendText(chat, msg)
+	botData.mu.Unlock()
+	if !found {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %s nao esta bloqueado.", target))
+		return
+	}
+	saveBotData()
+	logAction(gJID, sender.User, "", "liberarcmd", target, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s liberado neste grupo.", target))
 }
 
-func cmdSetRole(chat types.JID, msg *events.Message, args string) {
-	target := getMentionedJID(msg)
-	if target == nil || args == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #cargo @usuario administrador/moderador/auxiliar")
+func cmdMaintenance(chat types.JID, sender types.JID, args string) {
+	if strings.TrimSpace(args) == "" {
+		botData.mu.RLock()
+		list := formatDisabledList(botData.DisabledCmds)
+		botData.mu.RUnlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Em manutencao:*\n\n%s\nUso: #manutencao <comando|categoria> (repita para reativar)", list))
 		return
 	}
-	parts := strings.Fields(args)
-	role := "membro"
-	if len(parts) > 0 {
-		role = strings.ToLower(parts[len(parts)-1])
-	}
-	validRoles := map[string]bool{"administrador": true, "moderador": true, "auxiliar": true, "membro": true}
-	if !validRoles[role] {
-		sendText(chat, "*[OdinBOT]* Cargos validos: administrador, moderador, auxiliar, membro")
+	target, ok := parseCommandTarget(chat, args)
+	if !ok {
+		sendText(chat, "*[OdinBOT]* Comando ou categoria invalido.")
 		return
 	}
-	gJID := chat.String()
 	botData.mu.Lock()
-	if botData.Roles[gJID] == nil {
-		botData.Roles[gJID] = make(map[string]string)
+	removed := false
+	for i, d := range botData.DisabledCmds {
+		if d == target {
+			botData.DisabledCmds = append(botData.DisabledCmds[:i], botData.DisabledCmds[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		botData.DisabledCmds = append(botData.DisabledCmds, target)
 	}
-	botData.Roles[gJID][target.User] = role
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s agora e %s!", target.User, role))
+	if removed {
+		logAction("", sender.User, "", "manutencao", target+" reativado", SourceCommand)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %s reativado em todos os grupos.", target))
+	} else {
+		logAction("", sender.User, "", "manutencao", target+" desativado", SourceCommand)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %s em manutencao em todos os grupos.", target))
+	}
 }
 
 // ============================================================
-// Admin Commands
+// Group Message Templates
 // ============================================================
 
-func cmdBan(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para banir.")
-		return
+// Modelos padrao das mensagens de remocao/punicao. Placeholders:
+// {user}, {reason}, {admin}, {group}, {warns}
+var defaultGroupMessages = map[string]string{
+	"ban":         "@{user} foi banido!",
+	"antilink":    "@{user} removido por enviar link.",
+	"antifake":    "@{user} removido (numero estrangeiro - anti-fake).",
+	"listanegra":  "@{user} esta na lista negra e foi removido.",
+	"advertencia": "@{user} atingiu {warns} advertencias e foi banido + lista negra!",
+	"mute":        "@{user} foi mutado.",
+}
+
+var groupMessageKinds = []string{"ban", "antilink", "antifake", "listanegra", "advertencia", "mute"}
+
+func getGroupName(chat types.JID) string {
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	name := cfg.Name
+	botData.mu.RUnlock()
+	if name != "" {
+		return name
 	}
-	if isOwnerNumber(target.User) {
-		sendText(chat, "*[OdinBOT]* Nao posso banir o dono!")
-		return
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return ""
 	}
-	removeMember(chat, *target)
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi banido!", target.User))
+	return info.Name
 }
 
-func cmdWarn(chat types.JID, msg *events.Message, issuer types.JID, reason string) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para advertir.")
-		return
+var (
+	groupNameMu    sync.Mutex
+	groupNameCache = make(map[string]groupNameEntry) // JID -> nome consultado no WhatsApp
+)
+
+type groupNameEntry struct {
+	Name string
+	At   time.Time
+}
+
+// lookupGroupName e a versao somente leitura do getGroupName, para JIDs que podem
+// nem ser grupos do bot (lista negra, listas importadas): nao cria GroupConfig e
+// guarda o nome consultado por 1h, inclusive falhas.
+func lookupGroupName(groupJID string) string {
+	botData.mu.RLock()
+	cfg, ok := botData.Groups[groupJID]
+	name := ""
+	if ok {
+		name = cfg.Name
 	}
-	if isOwnerNumber(target.User) {
-		sendText(chat, "*[OdinBOT]* Nao posso advertir o dono!")
-		return
+	botData.mu.RUnlock()
+	if name != "" {
+		return name
 	}
-	if reason == "" {
-		reason = "Sem motivo especificado"
+
+	groupNameMu.Lock()
+	cached, ok := groupNameCache[groupJID]
+	groupNameMu.Unlock()
+	if ok && time.Since(cached.At) < time.Hour {
+		return cached.Name
 	}
-	gJID := chat.String()
-	w := Warning{
-		GroupJID: gJID,
-		UserJID:  target.User,
-		UserName: target.User,
-		Reason:   reason,
-		Date:     time.Now().Format("2006-01-02 15:04"),
-		IssuedBy: issuer.User,
+	jid, err := types.ParseJID(groupJID)
+	if err != nil || jid.Server != types.GroupServer {
+		return ""
 	}
-	botData.mu.Lock()
-	botData.Warnings[gJID] = append(botData.Warnings[gJID], w)
-	count := 0
-	for _, ww := range botData.Warnings[gJID] {
-		if ww.UserJID == target.User {
-			count++
-		}
+	if info, err := client.GetGroupInfo(context.Background(), jid); err == nil {
+		name = info.Name
 	}
-	botData.mu.Unlock()
-	saveBotData()
+	groupNameMu.Lock()
+	groupNameCache[groupJID] = groupNameEntry{Name: name, At: time.Now()}
+	groupNameMu.Unlock()
+	return name
+}
 
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s advertido! (%d/3)\nMotivo: %s", target.User, count, reason))
+func getGroupTemplate(groupJID, kind string) string {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	if cfg, ok := botData.Groups[groupJID]; ok {
+		if tpl, ok := cfg.Messages[kind]; ok && tpl != "" {
+			return tpl
+		}
+	}
+	return defaultGroupMessages[kind]
+}
 
-	if count >= 3 {
-		removeMember(chat, *target)
+// sendGroupTemplate envia a mensagem do tipo informado, mencionando o usuario afetado.
+func sendGroupTemplate(chat types.JID, kind string, user string, vars map[string]string) {
+	msg := getGroupTemplate(chat.String(), kind)
+	msg = strings.ReplaceAll(msg, "{user}", user)
+	if strings.Contains(msg, "{group}") {
+		msg = strings.ReplaceAll(msg, "{group}", getGroupName(chat))
+	}
+	for _, key := range []string{"reason", "admin", "warns"} {
+		msg = strings.ReplaceAll(msg, "{"+key+"}", vars[key])
+	}
+	sendMention(chat, "*[OdinBOT]* "+msg, []string{user})
+}
+
+// stripMentions remove as mencoes (@numero) de um texto de argumentos.
+func stripMentions(args string) string {
+	var words []string
+	for _, w := range strings.Fields(args) {
+		if !strings.HasPrefix(w, "@") {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func isGroupMessageKind(kind string) bool {
+	_, ok := defaultGroupMessages[kind]
+	return ok
+}
+
+func cmdSetGroupMessage(chat types.JID, sender types.JID, args string) {
+	parts := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	if len(parts) < 2 || !isGroupMessageKind(strings.ToLower(parts[0])) || strings.TrimSpace(parts[1]) == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #setmsg <tipo> <texto>\nTipos: "+strings.Join(groupMessageKinds, ", ")+"\nVariaveis: {user} {reason} {admin} {group} {warns}")
+		return
+	}
+	kind := strings.ToLower(parts[0])
+	text := strings.TrimSpace(parts[1])
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	if cfg.Messages == nil {
+		cfg.Messages = make(map[string]string)
+	}
+	cfg.Messages[kind] = text
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "setmsg", kind, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Mensagem de %s atualizada!", kind))
+}
+
+func cmdShowGroupMessages(chat types.JID, args string) {
+	gJID := chat.String()
+	kinds := groupMessageKinds
+	if kind := strings.ToLower(strings.TrimSpace(args)); isGroupMessageKind(kind) {
+		kinds = []string{kind}
+	}
+	msg := "*[OdinBOT] Mensagens do Grupo:*\n"
+	for _, kind := range kinds {
+		msg += fmt.Sprintf("\n*%s:*\n%s\n", kind, getGroupTemplate(gJID, kind))
+	}
+	sendText(chat, msg)
+}
+
+func cmdResetGroupMessage(chat types.JID, sender types.JID, args string) {
+	kind := strings.ToLower(strings.TrimSpace(args))
+	if kind != "todos" && !isGroupMessageKind(kind) {
+		sendText(chat, "*[OdinBOT]* Uso: #resetmsg <tipo|todos>\nTipos: "+strings.Join(groupMessageKinds, ", "))
+		return
+	}
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	if kind == "todos" {
+		cfg.Messages = nil
+	} else {
+		delete(cfg.Messages, kind)
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "resetmsg", kind, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Mensagem de %s restaurada para o padrao.", kind))
+}
+
+// ============================================================
+// Group Events
+// ============================================================
+
+func handleJoinedGroup(evt *events.JoinedGroup) {
+	sendText(evt.JID, fmt.Sprintf(
+		"*%s conectado!*\n\nOla! Sou o %s, bot do %s.\nUse *%smenu* para ver meus comandos.\nDono: %s",
+		BotName, BotName, OwnerName, DefaultPrefix, OwnerName,
+	))
+}
+
+// ============================================================
+// AFK System
+// ============================================================
+
+func cmdSetAfk(chat types.JID, sender types.JID, reason string) {
+	if reason == "" {
+		reason = "Sem motivo informado"
+	}
+	botData.mu.Lock()
+	botData.AfkUsers[sender.User] = reason
+	botData.mu.Unlock()
+	saveBotData()
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s agora esta AFK: %s", sender.User, reason))
+}
+
+func cmdRemoveAfk(chat types.JID, sender types.JID) {
+	botData.mu.Lock()
+	delete(botData.AfkUsers, sender.User)
+	botData.mu.Unlock()
+	saveBotData()
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s voltou da ausencia!", sender.User))
+}
+
+func cmdListAfk(chat types.JID) {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	if len(botData.AfkUsers) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhum usuario AFK.")
+		return
+	}
+	msg := "*[OdinBOT] Usuarios AFK:*\n\n"
+	for user, reason := range botData.AfkUsers {
+		msg += fmt.Sprintf("- @%s: %s\n", user, reason)
+	}
+	sendText(chat, msg)
+}
+
+func checkAfk(chat types.JID, sender types.JID, _ string) {
+	botData.mu.RLock()
+	reason, ok := botData.AfkUsers[sender.User]
+	botData.mu.RUnlock()
+	if ok {
+		botData.mu.Lock()
+		delete(botData.AfkUsers, sender.User)
+		botData.mu.Unlock()
+		saveBotData()
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s voltou! (estava AFK: %s)", sender.User, reason))
+	}
+}
+
+// ============================================================
+// Owner Commands
+// ============================================================
+
+func cmdAluguel(chat types.JID, sender types.JID, args string) {
+	parts := strings.Split(args, "|")
+	if len(parts) < 5 {
+		sendText(chat, "*[OdinBOT]* Uso: #aluguel grupo_jid|nome_grupo|dono_num|plano|valor\nEx: #aluguel 120363...@g.us|MeuGrupo|5511999|Mensal|50")
+		return
+	}
+	val := 0.0
+	fmt.Sscanf(strings.TrimSpace(parts[4]), "%f", &val)
+	ownerName := ""
+	if len(parts) > 5 {
+		ownerName = strings.TrimSpace(parts[5])
+	}
+	rental := Rental{
+		GroupJID:  strings.TrimSpace(parts[0]),
+		GroupName: strings.TrimSpace(parts[1]),
+		OwnerNum:  strings.TrimSpace(parts[2]),
+		OwnerName: ownerName,
+		Plan:      strings.TrimSpace(parts[3]),
+		Value:     val,
+		StartDate: time.Now().Format("2006-01-02"),
+		EndDate:   calcEndDate(strings.TrimSpace(parts[3])),
+		Active:    true,
+	}
+	botData.mu.Lock()
+	botData.Rentals = append(botData.Rentals, rental)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(rental.GroupJID, sender.User, rental.OwnerNum, "aluguel", fmt.Sprintf("%s R$%.2f", rental.Plan, rental.Value), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Aluguel registrado!\nGrupo: %s\nPlano: %s\nValor: R$%.2f\nVencimento: %s",
+		rental.GroupName, rental.Plan, rental.Value, rental.EndDate))
+}
+
+func cmdVerificarAluguel(chat types.JID) {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	if len(botData.Rentals) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhum aluguel registrado.")
+		return
+	}
+	msg := "*[OdinBOT] Alugueis:*\n\n"
+	for i, r := range botData.Rentals {
+		status := "Ativo"
+		if !r.Active {
+			status = "Expirado"
+		}
+		msg += fmt.Sprintf("%d. %s\n   Dono: %s | Plano: %s | R$%.2f\n   Venc: %s | Status: %s\n\n",
+			i+1, r.GroupName, r.OwnerNum, r.Plan, r.Value, r.EndDate, status)
+	}
+	sendText(chat, msg)
+}
+
+func cmdBroadcastAluguel(chat types.JID, sender types.JID, args string) {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	count := 0
+	for _, r := range botData.Rentals {
+		if r.Active {
+			jid, err := types.ParseJID(r.GroupJID)
+			if err == nil {
+				sendText(jid, fmt.Sprintf("*[OdinBOT - Aviso de Aluguel]*\n\n%s", args))
+				count++
+			}
+		}
+	}
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Broadcast enviado para %d grupos alugados.", count))
+	logAction("", sender.User, "", "bcaluguel", args, SourceCommand)
+}
+
+func cmdBroadcast(sender types.JID, args string) {
+	if args == "" {
+		return
+	}
+	groups, err := client.GetJoinedGroups(context.Background())
+	if err != nil {
+		return
+	}
+	logAction("", sender.User, "", "bc", args, SourceCommand)
+	for _, g := range groups {
+		sendText(g.JID, fmt.Sprintf("*[OdinBOT - Broadcast]*\n\n%s", args))
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func cmdJoin(link string) {
+	link = strings.TrimSpace(link)
+	if !strings.Contains(link, "chat.whatsapp.com") {
+		return
+	}
+	parts := strings.Split(link, "/")
+	code := parts[len(parts)-1]
+	_, err := client.JoinGroupWithLink(context.Background(), code)
+	if err != nil {
+		fmt.Printf("[ERRO] Entrar no grupo: %v\n", err)
+	}
+}
+
+func cmdLeaveGroup(chat types.JID) {
+	sendText(chat, "*[OdinBOT]* Saindo do grupo... Ate mais!")
+	time.Sleep(1 * time.Second)
+	_ = client.LeaveGroup(context.Background(), chat)
+}
+
+func cmdNuke(chat types.JID, sender types.JID) {
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+		return
+	}
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	var toRemove []types.JID
+	for _, p := range info.Participants {
+		if p.JID.User != client.Store.ID.User && !isOwnerNumber(p.JID.User) {
+			toRemove = append(toRemove, p.JID)
+		}
+	}
+	if len(toRemove) > 0 {
+		_, _ = client.UpdateGroupParticipants(context.Background(), chat, toRemove, whatsmeow.ParticipantChangeRemove)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Nuke executado. %d membros removidos.", len(toRemove)))
+		logAction(chat.String(), sender.User, "", "nuke", fmt.Sprintf("%d membros removidos", len(toRemove)), SourceCommand)
+	}
+}
+
+func cmdListGroups(chat types.JID) {
+	groups, err := client.GetJoinedGroups(context.Background())
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao listar grupos.")
+		return
+	}
+	msg := fmt.Sprintf("*[OdinBOT] Grupos (%d):*\n\n", len(groups))
+	for i, g := range groups {
+		msg += fmt.Sprintf("%d. %s\n   %s | %d membros\n\n", i+1, g.Name, g.JID.String(), len(g.Participants))
+	}
+	sendText(chat, msg)
+}
+
+func cmdSetRole(chat types.JID, msg *events.Message, sender types.JID, args string) {
+	target := getMentionedJID(msg)
+	if target == nil || args == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #cargo @usuario administrador/moderador/auxiliar")
+		return
+	}
+	parts := strings.Fields(args)
+	role := "membro"
+	if len(parts) > 0 {
+		role = strings.ToLower(parts[len(parts)-1])
+	}
+	validRoles := map[string]bool{"administrador": true, "moderador": true, "auxiliar": true, "membro": true}
+	if !validRoles[role] {
+		sendText(chat, "*[OdinBOT]* Cargos validos: administrador, moderador, auxiliar, membro")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	if botData.Roles[gJID] == nil {
+		botData.Roles[gJID] = make(map[string]string)
+	}
+	botData.Roles[gJID][target.User] = role
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, target.User, "cargo", role, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s agora e %s!", target.User, role))
+
+	// Administrador do bot tambem vira admin no WhatsApp
+	if role == "administrador" && chat.Server == "g.us" && !isGroupAdmin(chat, *target) && isBotAdmin(chat) {
+		_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{*target}, whatsmeow.ParticipantChangePromote)
+		if err != nil {
+			fmt.Printf("[ERRO] Promover cargo: %v\n", err)
+			return
+		}
+		logAction(gJID, BotName, target.User, "promover", "Cargo administrador", SourceAuto)
+	}
+}
+
+// ============================================================
+// Admin Commands
+// ============================================================
+
+func cmdBan(chat types.JID, msg *events.Message, sender types.JID, args string) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para banir.")
+		return
+	}
+	if isOwnerNumber(target.User) {
+		sendText(chat, "*[OdinBOT]* Nao posso banir o dono!")
+		return
+	}
+	if !outranks(chat, sender, *target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+	banUser(chat, *target, sender.User, stripMentions(args), SourceCommand)
+}
+
+// banUser remove o membro e anuncia com a mensagem de ban do grupo.
+func banUser(chat types.JID, target types.JID, admin string, reason string, source string) error {
+	if err := removeMember(chat, target); err != nil {
+		return err
+	}
+	logAction(chat.String(), admin, target.User, "ban", reason, source)
+	if reason == "" {
+		reason = "Sem motivo especificado"
+	}
+	sendGroupTemplate(chat, "ban", target.User, map[string]string{"reason": reason, "admin": admin})
+	return nil
+}
+
+// cmdTempBan remove o membro e o bloqueia neste grupo ate o fim do prazo.
+// Com "voltar" apos a duracao, o bot readiciona o membro quando o ban expira.
+func cmdTempBan(chat types.JID, msg *events.Message, sender types.JID, args string) {
+	target := getMentionedJID(msg)
+	parts := strings.Fields(stripMentions(args))
+	usage := "*[OdinBOT]* Uso: #tempban @user <duracao> [voltar] [motivo]\nDuracao: 30m, 12h, 7d, 2w (maximo 365d)"
+	if target == nil || len(parts) == 0 {
+		sendText(chat, usage)
+		return
+	}
+	if isOwnerNumber(target.User) {
+		sendText(chat, "*[OdinBOT]* Nao posso banir o dono!")
+		return
+	}
+	if !outranks(chat, sender, *target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+	dur, ok := parseBanDuration(parts[0])
+	if !ok {
+		sendText(chat, usage)
+		return
+	}
+	parts = parts[1:]
+	readd := false
+	if len(parts) > 0 && strings.ToLower(parts[0]) == "voltar" {
+		readd = true
+		parts = parts[1:]
+	}
+	reason := strings.Join(parts, " ")
+	if reason == "" {
+		reason = "Sem motivo especificado"
+	}
+	expires := time.Now().Add(dur).Format(blacklistTimeLayout)
+	entry := BlacklistEntry{
+		Number:    target.User,
+		Reason:    reason,
+		Date:      time.Now().Format("2006-01-02"),
+		AddedBy:   sender.User,
+		Scope:     ScopeGroup,
+		GroupJID:  chat.String(),
+		ExpiresAt: expires,
+		Readd:     readd,
+	}
+	botData.mu.Lock()
+	botData.Blacklist[blacklistKey(entry)] = entry
+	botData.mu.Unlock()
+	saveBotData()
+	banUser(chat, *target, sender.User, fmt.Sprintf("%s (ban temporario ate %s)", reason, expires), SourceCommand)
+}
+
+func cmdWarn(chat types.JID, msg *events.Message, issuer types.JID, reason string) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para advertir.")
+		return
+	}
+	if isOwnerNumber(target.User) {
+		sendText(chat, "*[OdinBOT]* Nao posso advertir o dono!")
+		return
+	}
+	if !outranks(chat, issuer, *target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+	warnUser(chat, *target, issuer.User, stripMentions(reason), SourceCommand)
+}
+
+// warnUser registra uma advertencia; na terceira o membro e removido e vai para a lista negra.
+func warnUser(chat types.JID, target types.JID, issuer string, reason string, source string) {
+	if reason == "" {
+		reason = "Sem motivo especificado"
+	}
+	gJID := chat.String()
+	w := Warning{
+		GroupJID: gJID,
+		UserJID:  target.User,
+		UserName: target.User,
+		Reason:   reason,
+		Date:     time.Now().Format("2006-01-02 15:04"),
+		IssuedBy: issuer,
+	}
+	botData.mu.Lock()
+	botData.Warnings[gJID] = append(botData.Warnings[gJID], w)
+	count := 0
+	for _, ww := range botData.Warnings[gJID] {
+		if ww.UserJID == target.User {
+			count++
+		}
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, issuer, target.User, "advertir", reason, source)
+
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s advertido! (%d/3)\nMotivo: %s", target.User, count, reason))
+
+	if count >= 3 {
+		removeMember(chat, target)
+		botData.mu.Lock()
+		botData.Blacklist[target.User] = BlacklistEntry{
+			Number:  target.User,
+			Reason:  "3 advertencias",
+			Date:    time.Now().Format("2006-01-02"),
+			AddedBy: "auto",
+		}
+		botData.mu.Unlock()
+		saveBotData()
+		logAction(gJID, BotName, target.User, "listanegra", "3 advertencias", SourceAuto)
+		sendGroupTemplate(chat, "advertencia", target.User, map[string]string{"reason": reason, "admin": issuer, "warns": strconv.Itoa(count)})
+		sendText(target.ToNonAD(), "*[OdinBOT]* Voce recebeu 3 advertencias e entrou na lista negra.\nSe acha que foi um engano, envie aqui: #apelar <sua explicacao>")
+	}
+}
+
+func addWarningAuto(groupJID string, user types.JID, reason string) {
+	w := Warning{
+		GroupJID: groupJID,
+		UserJID:  user.User,
+		UserName: user.User,
+		Reason:   reason,
+		Date:     time.Now().Format("2006-01-02 15:04"),
+		IssuedBy: "OdinBOT",
+	}
+	botData.mu.Lock()
+	botData.Warnings[groupJID] = append(botData.Warnings[groupJID], w)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(groupJID, BotName, user.User, "advertir", reason, SourceAuto)
+}
+
+func cmdCheckWarnings(chat types.JID, msg *events.Message) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para ver advertencias.")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.RLock()
+	warns := botData.Warnings[gJID]
+	botData.mu.RUnlock()
+	count := 0
+	details := ""
+	for _, w := range warns {
+		if w.UserJID == target.User {
+			count++
+			details += fmt.Sprintf("  %d. %s - %s\n", count, w.Reason, w.Date)
+		}
+	}
+	if count == 0 {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s nao tem advertencias.", target.User))
+	} else {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s tem %d advertencia(s):\n%s", target.User, count, details))
+	}
+}
+
+func cmdRemoveWarning(chat types.JID, msg *events.Message, sender types.JID) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para remover advertencia.")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	warns := botData.Warnings[gJID]
+	for i := len(warns) - 1; i >= 0; i-- {
+		if warns[i].UserJID == target.User {
+			botData.Warnings[gJID] = append(warns[:i], warns[i+1:]...)
+			break
+		}
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, target.User, "rm_adv", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Uma advertencia de @%s foi removida.", target.User))
+}
+
+func cmdClearWarnings(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	botData.mu.Lock()
+	delete(botData.Warnings, gJID)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "limpar_adv", "", SourceCommand)
+	sendText(chat, "*[OdinBOT]* Todas as advertencias do grupo foram limpas.")
+}
+
+func cmdListWarnings(chat types.JID) {
+	gJID := chat.String()
+	botData.mu.RLock()
+	warns := botData.Warnings[gJID]
+	botData.mu.RUnlock()
+	if len(warns) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhuma advertencia neste grupo.")
+		return
+	}
+	msg := "*[OdinBOT] Advertidos:*\n\n"
+	userWarns := make(map[string]int)
+	for _, w := range warns {
+		userWarns[w.UserJID]++
+	}
+	for user, count := range userWarns {
+		msg += fmt.Sprintf("- @%s: %d advertencia(s)\n", user, count)
+	}
+	sendText(chat, msg)
+}
+
+func cmdMute(chat types.JID, msg *events.Message, sender types.JID) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para mutar.")
+		return
+	}
+	if !outranks(chat, sender, *target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+	muteUser(chat, *target, sender.User, SourceCommand)
+}
+
+func muteUser(chat types.JID, target types.JID, admin string, source string) {
+	gJID := chat.String()
+	botData.mu.Lock()
+	if botData.MutedUsers[gJID] == nil {
+		botData.MutedUsers[gJID] = make(map[string]bool)
+	}
+	botData.MutedUsers[gJID][target.User] = true
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, admin, target.User, "mute", "", source)
+	sendGroupTemplate(chat, "mute", target.User, map[string]string{"admin": admin})
+}
+
+func cmdUnmute(chat types.JID, msg *events.Message, sender types.JID) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para desmutar.")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	if botData.MutedUsers[gJID] != nil {
+		delete(botData.MutedUsers[gJID], target.User)
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, target.User, "desmute", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi desmutado.", target.User))
+}
+
+func cmdPromote(chat types.JID, msg *events.Message, sender types.JID) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para promover.")
+		return
+	}
+	_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{*target}, whatsmeow.ParticipantChangePromote)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao promover.")
+		return
+	}
+	logAction(chat.String(), sender.User, target.User, "promover", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi promovido a admin!", target.User))
+}
+
+func cmdDemote(chat types.JID, msg *events.Message, sender types.JID) {
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Mencione alguem para rebaixar.")
+		return
+	}
+	if isProtectedAdmin(chat.String(), target.User) && !isOwnerNumber(sender.User) {
+		sendText(chat, "*[OdinBOT]* Este admin e protegido. Apenas o dono pode rebaixa-lo.")
+		return
+	}
+	_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{*target}, whatsmeow.ParticipantChangeDemote)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao rebaixar.")
+		return
+	}
+	logAction(chat.String(), sender.User, target.User, "rebaixar", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi rebaixado.", target.User))
+}
+
+func cmdToggleWelcome(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.Welcome = !cfg.Welcome
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.Welcome {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "bemvindo "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Bem-vindo %s!", status))
+}
+
+func cmdToggleAntilink(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.Antilink = !cfg.Antilink
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.Antilink {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "antilink "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-link %s!", status))
+}
+
+func cmdToggleAntifake(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.Antifake = !cfg.Antifake
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.Antifake {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "antifake "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-fake %s!", status))
+}
+
+func cmdToggleAntiPalavrao(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.AntiPalavrao = !cfg.AntiPalavrao
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.AntiPalavrao {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "antipalavra "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-palavrao %s!", status))
+}
+
+func cmdToggleAutoSticker(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.AutoSticker = !cfg.AutoSticker
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.AutoSticker {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "autosticker "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Auto-sticker %s!", status))
+}
+
+func cmdToggleAutoDL(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.AutoDL = !cfg.AutoDL
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.AutoDL {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "autodl "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Auto-download %s!", status))
+}
+
+func cmdToggleOnlyAdmin(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.OnlyAdm = !cfg.OnlyAdm
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !cfg.OnlyAdm {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "so_adm "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Modo so-admin %s!", status))
+}
+
+func cmdToggleAdminNotices(chat types.JID, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.AnnounceAdmins = !cfg.AnnounceAdmins
+	enabled := cfg.AnnounceAdmins
+	botData.mu.Unlock()
+	saveBotData()
+	status := "ativado"
+	if !enabled {
+		status = "desativado"
+	}
+	logAction(gJID, sender.User, "", "config", "avisoadm "+status, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Aviso de promocoes/rebaixamentos %s!", status))
+}
+
+func cmdCloseGroup(chat types.JID, sender types.JID) {
+	if settingsLocked(chat, sender) {
+		return
+	}
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+		return
+	}
+	_ = client.SetGroupAnnounce(context.Background(), chat, true)
+	logAction(chat.String(), sender.User, "", "fechargp", "", SourceCommand)
+	sendText(chat, "*[OdinBOT]* Grupo fechado! Somente admins podem enviar mensagens.")
+}
+
+func cmdOpenGroup(chat types.JID, sender types.JID) {
+	if settingsLocked(chat, sender) {
+		return
+	}
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+		return
+	}
+	_ = client.SetGroupAnnounce(context.Background(), chat, false)
+	logAction(chat.String(), sender.User, "", "abrirgp", "", SourceCommand)
+	sendText(chat, "*[OdinBOT]* Grupo aberto! Todos podem enviar mensagens.")
+}
+
+func cmdSetGroupName(chat types.JID, sender types.JID, name string) {
+	if name == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #nomegp Novo Nome")
+		return
+	}
+	if settingsLocked(chat, sender) {
+		return
+	}
+	_ = client.SetGroupName(context.Background(), chat, name)
+	logAction(chat.String(), sender.User, "", "nomegp", name, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Nome do grupo alterado para: %s", name))
+}
+
+func cmdSetGroupDesc(chat types.JID, sender types.JID, desc string) {
+	if desc == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #descgp Nova descricao")
+		return
+	}
+	if settingsLocked(chat, sender) {
+		return
+	}
+	_ = client.SetGroupTopic(context.Background(), chat, "", "", desc)
+	logAction(chat.String(), sender.User, "", "descgp", desc, SourceCommand)
+	sendText(chat, "*[OdinBOT]* Descricao do grupo atualizada!")
+}
+
+func cmdGetGroupLink(chat types.JID) {
+	link, err := client.GetGroupInviteLink(context.Background(), chat, false)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao obter link. Preciso ser admin.")
+		return
+	}
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Link do grupo:\nhttps://chat.whatsapp.com/%s", link))
+}
+
+func cmdTagAll(chat types.JID, text string) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	if text == "" {
+		text = "Atencao membros!"
+	}
+	msg := fmt.Sprintf("*[OdinBOT] %s*\n\n", text)
+	var mentions []string
+	for _, p := range info.Participants {
+		msg += fmt.Sprintf("@%s\n", p.JID.User)
+		mentions = append(mentions, p.JID.User)
+	}
+	sendMention(chat, msg, mentions)
+}
+
+func cmdHideTag(chat types.JID, text string) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	if text == "" {
+		text = "."
+	}
+	var mentions []string
+	for _, p := range info.Participants {
+		mentions = append(mentions, p.JID.User)
+	}
+	sendMention(chat, text, mentions)
+}
+
+func cmdBanGhost(chat types.JID, sender types.JID) {
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+		return
+	}
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	var ghosts []types.JID
+	for _, p := range info.Participants {
+		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
+			_, err := client.GetProfilePictureInfo(context.Background(), p.JID, &whatsmeow.GetProfilePictureParams{})
+			if err != nil {
+				ghosts = append(ghosts, p.JID)
+			}
+		}
+	}
+	if len(ghosts) > 0 {
+		_, _ = client.UpdateGroupParticipants(context.Background(), chat, ghosts, whatsmeow.ParticipantChangeRemove)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %d ghosts removidos!", len(ghosts)))
+		logAction(chat.String(), sender.User, "", "banghost", fmt.Sprintf("%d membros removidos", len(ghosts)), SourceCommand)
+	} else {
+		sendText(chat, "*[OdinBOT]* Nenhum ghost encontrado.")
+	}
+}
+
+func cmdBanFakes(chat types.JID, sender types.JID) {
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+		return
+	}
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	var fakes []types.JID
+	for _, p := range info.Participants {
+		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
+			if !strings.HasPrefix(p.JID.User, "55") {
+				fakes = append(fakes, p.JID)
+			}
+		}
+	}
+	if len(fakes) > 0 {
+		_, _ = client.UpdateGroupParticipants(context.Background(), chat, fakes, whatsmeow.ParticipantChangeRemove)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %d fakes (numeros estrangeiros) removidos!", len(fakes)))
+		logAction(chat.String(), sender.User, "", "banfakes", fmt.Sprintf("%d membros removidos", len(fakes)), SourceCommand)
+	} else {
+		sendText(chat, "*[OdinBOT]* Nenhum fake encontrado.")
+	}
+}
+
+func cmdSorteio(chat types.JID) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	if len(info.Participants) == 0 {
+		return
+	}
+	winner := info.Participants[rand.Intn(len(info.Participants))]
+	sendText(chat, fmt.Sprintf("*[OdinBOT] SORTEIO!*\n\nO sortudo(a) e: @%s! Parabens!", winner.JID.User))
+}
+
+func cmdGroupStatus(chat types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	boolStr := func(b bool) string {
+		if b {
+			return "ON"
+		}
+		return "OFF"
+	}
+	slowMode := "OFF"
+	if cfg.SlowMode > 0 {
+		slowMode = fmt.Sprintf("%ds", cfg.SlowMode)
+	}
+	botData.mu.RLock()
+	locked := cfg.Lock != nil
+	adminNotices := cfg.AnnounceAdmins
+	mediaFilters := formatMediaFilters(cfg.MediaFilters)
+	botData.mu.RUnlock()
+	msg := fmt.Sprintf(`*[OdinBOT] Status do Grupo:*
+
+- Bem-vindo: %s
+- Anti-link: %s
+- Anti-fake: %s
+- Anti-palavrao: %s
+- Anti-trava: %s
+- Auto-sticker: %s
+- Auto-download: %s
+- So admin: %s
+- Slow mode: %s
+- Config travada: %s
+- Aviso de admins: %s
+- Filtros de midia: %s
+- NSFW: %s
+- Prefixo: %s
+- Ativo: %s`,
+		boolStr(cfg.Welcome), boolStr(cfg.Antilink), boolStr(cfg.Antifake),
+		boolStr(cfg.AntiPalavrao), boolStr(cfg.AntiTrava), boolStr(cfg.AutoSticker), boolStr(cfg.AutoDL),
+		boolStr(cfg.OnlyAdm), slowMode, boolStr(locked), boolStr(adminNotices), mediaFilters, boolStr(cfg.NSFW), cfg.Prefix, boolStr(cfg.Active))
+	sendText(chat, msg)
+}
+
+func cmdAddBadWord(chat types.JID, sender types.JID, word string) {
+	if word == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #addpalavra <palavra>")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	botData.BadWords[gJID] = append(botData.BadWords[gJID], word)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "addpalavra", word, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Palavra '%s' adicionada a lista proibida.", word))
+}
+
+func cmdDelBadWord(chat types.JID, sender types.JID, word string) {
+	if word == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #delpalavra <palavra>")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	words := botData.BadWords[gJID]
+	for i, w := range words {
+		if strings.EqualFold(w, word) {
+			botData.BadWords[gJID] = append(words[:i], words[i+1:]...)
+			break
+		}
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "delpalavra", word, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Palavra '%s' removida da lista.", word))
+}
+
+func cmdListBadWords(chat types.JID) {
+	gJID := chat.String()
+	botData.mu.RLock()
+	words := botData.BadWords[gJID]
+	botData.mu.RUnlock()
+	if len(words) == 0 {
+		sendText(chat, "*[OdinBOT]* Lista de palavras proibidas vazia.")
+		return
+	}
+	msg := "*[OdinBOT] Palavras Proibidas:*\n\n"
+	for i, w := range words {
+		msg += fmt.Sprintf("%d. %s\n", i+1, w)
+	}
+	sendText(chat, msg)
+}
+
+func cmdAddNote(chat types.JID, text string) {
+	if text == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #anotar <texto>")
+		return
+	}
+	gJID := chat.String()
+	botData.mu.Lock()
+	botData.Notes[gJID] = append(botData.Notes[gJID], text)
+	botData.mu.Unlock()
+	saveBotData()
+	sendText(chat, "*[OdinBOT]* Nota adicionada!")
+}
+
+func cmdShowNotes(chat types.JID) {
+	gJID := chat.String()
+	botData.mu.RLock()
+	notes := botData.Notes[gJID]
+	botData.mu.RUnlock()
+	if len(notes) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhuma anotacao.")
+		return
+	}
+	msg := "*[OdinBOT] Anotacoes:*\n\n"
+	for i, n := range notes {
+		msg += fmt.Sprintf("%d. %s\n", i+1, n)
+	}
+	sendText(chat, msg)
+}
+
+func cmdDelNote(chat types.JID, idx string) {
+	gJID := chat.String()
+	var i int
+	fmt.Sscanf(idx, "%d", &i)
+	i--
+	botData.mu.Lock()
+	notes := botData.Notes[gJID]
+	if i >= 0 && i < len(notes) {
+		botData.Notes[gJID] = append(notes[:i], notes[i+1:]...)
+		botData.mu.Unlock()
+		saveBotData()
+		sendText(chat, "*[OdinBOT]* Nota removida!")
+	} else {
+		botData.mu.Unlock()
+		sendText(chat, "*[OdinBOT]* Numero da nota invalido.")
+	}
+}
+
+func cmdGroupInfo(chat types.JID) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao obter info do grupo.")
+		return
+	}
+	admins := 0
+	for _, p := range info.Participants {
+		if p.IsAdmin || p.IsSuperAdmin {
+			admins++
+		}
+	}
+	msg := fmt.Sprintf(`*[OdinBOT] Info do Grupo:*
+
+- Nome: %s
+- Membros: %d
+- Admins: %d
+- Descricao: %s`,
+		info.Name, len(info.Participants), admins, info.Topic)
+	sendText(chat, msg)
+}
+
+func cmdListAdmins(chat types.JID) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	msg := "*[OdinBOT] Admins:*\n\n"
+	var mentions []string
+	for _, p := range info.Participants {
+		if p.IsAdmin || p.IsSuperAdmin {
+			role := "Admin"
+			if p.IsSuperAdmin {
+				role = "Super Admin"
+			}
+			msg += fmt.Sprintf("- @%s (%s)\n", p.JID.User, role)
+			mentions = append(mentions, p.JID.User)
+		}
+	}
+	sendMention(chat, msg, mentions)
+}
+
+func cmdRoleta(chat types.JID, sender types.JID) {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	var nonAdmin []types.JID
+	for _, p := range info.Participants {
+		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
+			nonAdmin = append(nonAdmin, p.JID)
+		}
+	}
+	if len(nonAdmin) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhum membro para a roleta.")
+		return
+	}
+	victim := nonAdmin[rand.Intn(len(nonAdmin))]
+	sendText(chat, fmt.Sprintf("*[OdinBOT] ROLETA RUSSA!*\n\nA bala acertou @%s!", victim.User))
+	removeMember(chat, victim)
+	logAction(chat.String(), sender.User, victim.User, "roleta", "", SourceCommand)
+}
+
+// ============================================================
+// Blacklist Commands
+// ============================================================
+
+const blacklistTimeLayout = "2006-01-02 15:04"
+
+// blacklistKey monta a chave do mapa. Entradas globais continuam usando apenas o
+// numero, como nas versoes anteriores do botdata.json.
+func blacklistKey(b BlacklistEntry) string {
+	switch b.Scope {
+	case ScopeGroup:
+		return b.Number + "|" + ScopeGroup + ":" + b.GroupJID
+	case ScopeOwner:
+		return b.Number + "|" + ScopeOwner + ":" + b.Owner
+	}
+	return b.Number
+}
+
+func blacklistExpired(b BlacklistEntry, now time.Time) bool {
+	if b.ExpiresAt == "" {
+		return false
+	}
+	exp, err := time.ParseInLocation(blacklistTimeLayout, b.ExpiresAt, time.Local)
+	return err == nil && !now.Before(exp)
+}
+
+// groupOwnerLocked retorna o dono (aluguel) do grupo. Exige botData.mu travado.
+func groupOwnerLocked(groupJID string) string {
+	for _, r := range botData.Rentals {
+		if r.GroupJID == groupJID {
+			return r.OwnerNum
+		}
+	}
+	return ""
+}
+
+// findBlacklistEntry procura uma entrada valida para o numero no grupo informado,
+// considerando os escopos global, do grupo e do dono do grupo.
+func findBlacklistEntry(number string, groupJID string) (BlacklistEntry, bool) {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	now := time.Now()
+	owner := groupOwnerLocked(groupJID)
+	for key, b := range botData.Blacklist {
+		if (b.Number != number && key != number) || blacklistExpired(b, now) {
+			continue
+		}
+		switch b.Scope {
+		case ScopeGroup:
+			if b.GroupJID == groupJID {
+				return b, true
+			}
+		case ScopeOwner:
+			if owner != "" && b.Owner == owner {
+				return b, true
+			}
+		default:
+			return b, true
+		}
+	}
+	return BlacklistEntry{}, false
+}
+
+// Limite das duracoes: valores maiores estourariam o time.Duration
+const maxBanDuration = 365 * 24 * time.Hour
+
+// parseBanDuration aceita m (minutos), h (horas), d (dias) e w (semanas), ate 365 dias.
+func parseBanDuration(s string) (time.Duration, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, false
+	}
+	// Compara antes de multiplicar para nao estourar
+	if int64(n) > int64(maxBanDuration/unit) {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// looksLikeDuration indica um "7d" que o parseBanDuration recusou, para nao
+// virar parte do motivo e deixar a entrada permanente.
+func looksLikeDuration(s string) bool {
+	s = strings.ToLower(s)
+	if len(s) < 2 || !strings.ContainsRune("mhdw", rune(s[len(s)-1])) {
+		return false
+	}
+	for _, r := range s[:len(s)-1] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func describeBlacklistScope(b BlacklistEntry) string {
+	switch b.Scope {
+	case ScopeGroup:
+		if name := lookupGroupName(b.GroupJID); name != "" {
+			return "grupo " + name
+		}
+		return "grupo " + b.GroupJID
+	case ScopeOwner:
+		return "grupos do dono " + b.Owner
+	}
+	return "global"
+}
+
+// cmdAddBlacklist: #listanegra <numero> [varrer] [global|grupo|dono[:numero]] [duracao] [motivo]
+// Com "varrer" o numero e removido na hora de todos os grupos onde o bot e admin.
+func cmdAddBlacklist(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(args)
+	number := strings.TrimPrefix(parts[0], "@")
+	entry := BlacklistEntry{
+		Number:  number,
+		Reason:  "Adicionado manualmente",
+		Date:    time.Now().Format("2006-01-02"),
+		AddedBy: sender.User,
+	}
+	parts = parts[1:]
+	sweep := false
+	if len(parts) > 0 && strings.ToLower(parts[0]) == "varrer" {
+		sweep = true
+		parts = parts[1:]
+	}
+	if len(parts) > 0 {
+		scope := strings.ToLower(parts[0])
+		switch {
+		case scope == ScopeGlobal:
+			parts = parts[1:]
+		case scope == ScopeGroup:
+			if chat.Server != "g.us" {
+				sendText(chat, "*[OdinBOT]* Use o escopo grupo dentro do grupo.")
+				return
+			}
+			entry.Scope, entry.GroupJID = ScopeGroup, chat.String()
+			parts = parts[1:]
+		case scope == ScopeOwner || strings.HasPrefix(scope, ScopeOwner+":"):
+			owner := strings.TrimPrefix(strings.TrimPrefix(scope, ScopeOwner), ":")
+			if owner == "" {
+				botData.mu.RLock()
+				owner = groupOwnerLocked(chat.String())
+				botData.mu.RUnlock()
+			}
+			if owner == "" {
+				sendText(chat, "*[OdinBOT]* Informe o dono: dono:5592999999999")
+				return
+			}
+			entry.Scope, entry.Owner = ScopeOwner, owner
+			parts = parts[1:]
+		}
+	}
+	if len(parts) > 0 {
+		if dur, ok := parseBanDuration(parts[0]); ok {
+			entry.ExpiresAt = time.Now().Add(dur).Format(blacklistTimeLayout)
+			parts = parts[1:]
+		} else if looksLikeDuration(parts[0]) {
+			sendText(chat, "*[OdinBOT]* Duracao invalida (maximo 365d).")
+			return
+		}
+	}
+	if len(parts) > 0 {
+		entry.Reason = strings.Join(parts, " ")
+	}
+
+	botData.mu.Lock()
+	botData.Blacklist[blacklistKey(entry)] = entry
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(entry.GroupJID, sender.User, number, "listanegra", entry.Reason, SourceCommand)
+	msg := fmt.Sprintf("*[OdinBOT]* %s adicionado a lista negra (%s).", number, describeBlacklistScope(entry))
+	if entry.ExpiresAt != "" {
+		msg += "\nExpira em: " + entry.ExpiresAt
+	}
+	sendText(chat, msg)
+	if sweep {
+		sendText(chat, formatSweepReport(sweepBlacklist([]string{number}, sender.User)))
+	}
+}
+
+// sweepBlacklist percorre todos os grupos do bot e remove quem esta na lista negra
+// (apenas os numeros informados, ou a lista inteira se numbers for nil).
+// Retorna uma linha por grupo onde havia alguem para remover.
+func sweepBlacklist(numbers []string, actor string) []string {
+	groups, err := client.GetJoinedGroups(context.Background())
+	if err != nil {
+		fmt.Printf("[ERRO] Varredura da lista negra: %v\n", err)
+		return []string{"Erro ao listar grupos: " + err.Error()}
+	}
+
+	candidates := make(map[string]bool)
+	if numbers != nil {
+		for _, n := range numbers {
+			candidates[n] = true
+		}
+	} else {
+		botData.mu.RLock()
+		for key, b := range botData.Blacklist {
+			if b.Number == "" {
+				b.Number = key
+			}
+			candidates[b.Number] = true
+		}
+		botData.mu.RUnlock()
+	}
+
+	var report []string
+	for _, g := range groups {
+		gJID := g.JID.String()
+		botAdmin := false
+		var targets []types.JID
+		for _, p := range g.Participants {
+			if client.Store.ID != nil && p.JID.User == client.Store.ID.User {
+				botAdmin = p.IsAdmin || p.IsSuperAdmin
+				continue
+			}
+			if !candidates[p.JID.User] || isOwnerNumber(p.JID.User) {
+				continue
+			}
+			if isBlacklisted(p.JID.User, gJID) {
+				targets = append(targets, p.JID)
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		name := g.Name
+		if name == "" {
+			name = gJID
+		}
+		if !botAdmin {
+			report = append(report, fmt.Sprintf("%s: bot nao e admin (%d na lista)", name, len(targets)))
+			continue
+		}
+		removed, failed := 0, 0
+		for _, t := range targets {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			_, err := client.UpdateGroupParticipants(ctx, g.JID, []types.JID{t}, whatsmeow.ParticipantChangeRemove)
+			cancel()
+			if err != nil {
+				fmt.Printf("[ERRO] Varredura %s em %s: %v\n", t.User, gJID, err)
+				failed++
+				continue
+			}
+			removed++
+			logAction(gJID, actor, t.User, "listanegra", "Varredura da lista negra", SourceCommand)
+			time.Sleep(500 * time.Millisecond)
+		}
+		line := fmt.Sprintf("%s: %d removido(s)", name, removed)
+		if failed > 0 {
+			line += fmt.Sprintf(", %d falha(s)", failed)
+		}
+		report = append(report, line)
+	}
+	return report
+}
+
+func formatSweepReport(report []string) string {
+	if len(report) == 0 {
+		return "*[OdinBOT]* Varredura concluida: ninguem da lista negra nos grupos."
+	}
+	return "*[OdinBOT] Varredura da lista negra:*\n\n" + strings.Join(report, "\n")
+}
+
+func cmdSweepBlacklist(chat types.JID, sender types.JID) {
+	sendText(chat, "*[OdinBOT]* Varrendo todos os grupos...")
+	sendText(chat, formatSweepReport(sweepBlacklist(nil, sender.User)))
+}
+
+// cmdRemoveBlacklist remove todas as entradas do numero, em qualquer escopo.
+func cmdRemoveBlacklist(chat types.JID, sender types.JID, number string) {
+	number = strings.TrimPrefix(strings.TrimSpace(number), "@")
+	removed := 0
+	shared := false
+	botData.mu.Lock()
+	for key, b := range botData.Blacklist {
+		if b.Number == number || key == number {
+			delete(botData.Blacklist, key)
+			removed++
+			shared = shared || b.Source != ""
+		}
+	}
+	// Sem isso a proxima sincronizacao traria o numero de volta
+	if shared && !containsString(botData.BlacklistOverrides, number) {
+		botData.BlacklistOverrides = append(botData.BlacklistOverrides, number)
+	}
+	botData.mu.Unlock()
+	if removed == 0 {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* %s nao esta na lista negra.", number))
+		return
+	}
+	saveBotData()
+	logAction(chat.String(), sender.User, number, "tirardalista", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s removido da lista negra.", number))
+}
+
+func cmdShowBlacklist(chat types.JID) {
+	botData.mu.RLock()
+	entries := make([]BlacklistEntry, 0, len(botData.Blacklist))
+	for _, b := range botData.Blacklist {
+		entries = append(entries, b)
+	}
+	botData.mu.RUnlock()
+	if len(entries) == 0 {
+		sendText(chat, "*[OdinBOT]* Lista negra vazia.")
+		return
+	}
+	msg := "*[OdinBOT] Lista Negra:*\n\n"
+	for i, b := range entries {
+		msg += fmt.Sprintf("%d. %s - %s (%s) [%s]", i+1, b.Number, b.Reason, b.Date, describeBlacklistScope(b))
+		if b.ExpiresAt != "" {
+			msg += " ate " + b.ExpiresAt
+		}
+		if b.Source != "" {
+			msg += " | fonte: " + b.Source
+		}
+		if b.Evidence != "" {
+			msg += "\n   Evidencia: " + b.Evidence
+		}
+		msg += "\n"
+	}
+	sendText(chat, msg)
+}
+
+// groupBlacklistTarget aceita mencao ou numero como primeiro argumento.
+// Um numero digitado tem prioridade; mencao ou mensagem respondida so valem sem ele.
+func groupBlacklistTarget(msg *events.Message, args string) (string, []string) {
+	if fields := strings.Fields(args); len(fields) > 0 {
+		if number := strings.TrimLeft(fields[0], "@+"); isPhoneNumber(number) {
+			return number, strings.Fields(stripMentions(strings.Join(fields[1:], " ")))
+		}
+	}
+	parts := strings.Fields(stripMentions(args))
+	if target := getMentionedJID(msg); target != nil {
+		return target.User, parts
+	}
+	return "", nil
+}
+
+func isPhoneNumber(s string) bool {
+	if len(s) < 8 || len(s) > 15 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isParticipant consulta se o usuario esta no grupo agora.
+func isParticipant(chat types.JID, user string) bool {
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return false
+	}
+	for _, p := range info.Participants {
+		if p.JID.User == user {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdAddGroupBlacklist: #listanegragp <@user|numero> [duracao] [motivo]
+// Lista negra gerenciada pelos admins, valida apenas neste grupo.
+func cmdAddGroupBlacklist(chat types.JID, msg *events.Message, sender types.JID, args string) {
+	number, parts := groupBlacklistTarget(msg, args)
+	if number == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #listanegragp @user|numero [duracao] [motivo]")
+		return
+	}
+	if isOwnerNumber(number) {
+		sendText(chat, "*[OdinBOT]* Nao posso colocar o dono na lista negra!")
+		return
+	}
+	target := types.NewJID(number, types.DefaultUserServer)
+	if !outranks(chat, sender, target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+	entry := BlacklistEntry{
+		Number:   number,
+		Reason:   "Adicionado pelos admins do grupo",
+		Date:     time.Now().Format("2006-01-02"),
+		AddedBy:  sender.User,
+		Scope:    ScopeGroup,
+		GroupJID: chat.String(),
+	}
+	if len(parts) > 0 {
+		if dur, ok := parseBanDuration(parts[0]); ok {
+			entry.ExpiresAt = time.Now().Add(dur).Format(blacklistTimeLayout)
+			parts = parts[1:]
+		} else if looksLikeDuration(parts[0]) {
+			sendText(chat, "*[OdinBOT]* Duracao invalida (maximo 365d).")
+			return
+		}
+	}
+	if len(parts) > 0 {
+		entry.Reason = strings.Join(parts, " ")
+	}
+	botData.mu.Lock()
+	botData.Blacklist[blacklistKey(entry)] = entry
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(entry.GroupJID, sender.User, number, "listanegra", entry.Reason, SourceCommand)
+
+	reply := fmt.Sprintf("*[OdinBOT]* %s adicionado a lista negra do grupo.", number)
+	if entry.ExpiresAt != "" {
+		reply += "\nExpira em: " + entry.ExpiresAt
+	}
+	sendText(chat, reply)
+	if isParticipant(chat, number) {
+		removeMember(chat, target)
+	}
+}
+
+// cmdRemoveGroupBlacklist remove apenas a entrada deste grupo; entradas globais
+// ou do dono continuam valendo e so o dono do bot pode tira-las.
+func cmdRemoveGroupBlacklist(chat types.JID, msg *events.Message, sender types.JID, args string) {
+	number, _ := groupBlacklistTarget(msg, args)
+	if number == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #tirardalistagp @user|numero")
+		return
+	}
+	key := blacklistKey(BlacklistEntry{Number: number, Scope: ScopeGroup, GroupJID: chat.String()})
+	botData.mu.Lock()
+	_, ok := botData.Blacklist[key]
+	delete(botData.Blacklist, key)
+	botData.mu.Unlock()
+	if !ok {
+		if entry, found := findBlacklistEntry(number, chat.String()); found {
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* %s esta na lista negra (%s). Apenas o dono do bot pode remover.", number, describeBlacklistScope(entry)))
+		} else {
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* %s nao esta na lista negra do grupo.", number))
+		}
+		return
+	}
+	saveBotData()
+	logAction(chat.String(), sender.User, number, "tirardalista", "", SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s removido da lista negra do grupo.", number))
+}
+
+// cmdShowGroupBlacklist lista tudo que vale neste grupo, indicando a origem.
+func cmdShowGroupBlacklist(chat types.JID) {
+	gJID := chat.String()
+	now := time.Now()
+	var entries []BlacklistEntry
+	botData.mu.RLock()
+	owner := groupOwnerLocked(gJID)
+	for _, b := range botData.Blacklist {
+		if blacklistExpired(b, now) {
+			continue
+		}
+		if (b.Scope == ScopeGroup && b.GroupJID == gJID) || (b.Scope == ScopeOwner && owner != "" && b.Owner == owner) || b.Scope == "" || b.Scope == ScopeGlobal {
+			entries = append(entries, b)
+		}
+	}
+	botData.mu.RUnlock()
+	if len(entries) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhum numero na lista negra deste grupo.")
+		return
+	}
+	msg := "*[OdinBOT] Lista Negra (neste grupo):*\n\n"
+	for i, b := range entries {
+		origin := "global"
+		switch b.Scope {
+		case ScopeGroup:
+			origin = "admins do grupo"
+		case ScopeOwner:
+			origin = "dono " + b.Owner
+		}
+		msg += fmt.Sprintf("%d. %s - %s (%s) [%s]", i+1, b.Number, b.Reason, b.Date, origin)
+		if b.ExpiresAt != "" {
+			msg += " ate " + b.ExpiresAt
+		}
+		msg += "\n"
+	}
+	sendText(chat, msg)
+}
+
+// blacklistExpiryChecker remove as entradas vencidas e, quando pedido no
+// #tempban, readiciona o membro ao grupo.
+func blacklistExpiryChecker() {
+	for {
+		time.Sleep(1 * time.Minute)
+		now := time.Now()
+		var expired []BlacklistEntry
+		botData.mu.Lock()
+		for key, b := range botData.Blacklist {
+			if blacklistExpired(b, now) {
+				expired = append(expired, b)
+				delete(botData.Blacklist, key)
+			}
+		}
+		botData.mu.Unlock()
+		if len(expired) == 0 {
+			continue
+		}
+		saveBotData()
+
+		for _, b := range expired {
+			logAction(b.GroupJID, BotName, b.Number, "tirardalista", "Ban temporario expirou", SourceAuto)
+			if !b.Readd || b.GroupJID == "" {
+				continue
+			}
+			group, err := types.ParseJID(b.GroupJID)
+			if err != nil {
+				continue
+			}
+			user := types.NewJID(b.Number, types.DefaultUserServer)
+			if err := addMember(group, user); err != nil {
+				fmt.Printf("[ERRO] Readicionar %s apos ban temporario: %v\n", b.Number, err)
+				continue
+			}
+			logAction(b.GroupJID, BotName, b.Number, "readicionar", "Fim do ban temporario", SourceAuto)
+			sendMention(group, fmt.Sprintf("*[OdinBOT]* @%s voltou ao grupo: o ban temporario terminou.", b.Number), []string{b.Number})
+		}
+	}
+}
+
+// ============================================================
+// Blacklist Sharing
+// ============================================================
+
+var blacklistCSVHeader = []string{"number", "reason", "date", "added_by", "scope", "group_jid", "owner", "expires_at", "source"}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeBlacklistCSV(entries []BlacklistEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(blacklistCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, b := range entries {
+		if err := w.Write([]string{b.Number, b.Reason, b.Date, b.AddedBy, b.Scope, b.GroupJID, b.Owner, b.ExpiresAt, b.Source}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// parseBlacklistData le uma lista em JSON (array ou o mapa do botdata.json) ou CSV.
+// No CSV so a primeira coluna (numero) e obrigatoria.
+func parseBlacklistData(data []byte) ([]BlacklistEntry, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	var entries []BlacklistEntry
+	switch {
+	case len(data) == 0:
+		return nil, nil
+	case data[0] == '[':
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	case data[0] == '{':
+		var m map[string]BlacklistEntry
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		for key, b := range m {
+			if b.Number == "" {
+				b.Number = key
+			}
+			entries = append(entries, b)
+		}
+	default:
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, rec := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "number") {
+				continue
+			}
+			field := func(n int) string {
+				if n < len(rec) {
+					return strings.TrimSpace(rec[n])
+				}
+				return ""
+			}
+			entries = append(entries, BlacklistEntry{
+				Number: field(0), Reason: field(1), Date: field(2), AddedBy: field(3), Scope: field(4),
+				GroupJID: field(5), Owner: field(6), ExpiresAt: field(7), Source: field(8),
+			})
+		}
+	}
+
+	valid := entries[:0]
+	for _, b := range entries {
+		b.Number = strings.TrimPrefix(strings.TrimSpace(b.Number), "+")
+		if _, err := strconv.ParseUint(b.Number, 10, 64); err != nil {
+			continue
+		}
+		if b.Reason == "" {
+			b.Reason = "Lista compartilhada"
+		}
+		if b.Date == "" {
+			b.Date = time.Now().Format("2006-01-02")
+		}
+		valid = append(valid, b)
+	}
+	return valid, nil
+}
+
+// mergeSharedBlacklist substitui as entradas vindas de source pelas novas.
+// Entradas locais e numeros liberados pelo dono (#tirardalista) sempre prevalecem.
+func mergeSharedBlacklist(source string, entries []BlacklistEntry) (added int, skipped int) {
+	now := time.Now()
+	botData.mu.Lock()
+	defer botData.mu.Unlock()
+	for key, b := range botData.Blacklist {
+		if b.Source == source {
+			delete(botData.Blacklist, key)
+		}
+	}
+	for _, b := range entries {
+		if blacklistExpired(b, now) || containsString(botData.BlacklistOverrides, b.Number) {
+			skipped++
+			continue
+		}
+		b.Source = source
+		key := blacklistKey(b)
+		if _, exists := botData.Blacklist[key]; exists {
+			skipped++
+			continue
+		}
+		botData.Blacklist[key] = b
+		added++
+	}
+	return added, skipped
+}
+
+func sendDocument(chat types.JID, data []byte, fileName string, mimetype string, caption string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	up, err := client.Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		return err
+	}
+	msg := &waE2E.Message{
+		DocumentMessage: &waE2E.DocumentMessage{
+			URL:           proto.String(up.URL),
+			DirectPath:    proto.String(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    proto.Uint64(up.FileLength),
+			Mimetype:      proto.String(mimetype),
+			FileName:      proto.String(fileName),
+			Title:         proto.String(fileName),
+			Caption:       proto.String(caption),
+		},
+	}
+	_, err = client.SendMessage(ctx, chat, msg)
+	return err
+}
+
+// cmdExportBlacklist: #exportlista [csv|json] - envia a lista negra no privado do dono.
+func cmdExportBlacklist(chat types.JID, sender types.JID, args string) {
+	format := strings.ToLower(strings.TrimSpace(args))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		sendText(chat, "*[OdinBOT]* Uso: #exportlista [json|csv]")
+		return
+	}
+	now := time.Now()
+	botData.mu.RLock()
+	entries := make([]BlacklistEntry, 0, len(botData.Blacklist))
+	for _, b := range botData.Blacklist {
+		if !blacklistExpired(b, now) {
+			entries = append(entries, b)
+		}
+	}
+	botData.mu.RUnlock()
+
+	var data []byte
+	var err error
+	mimetype := "application/json"
+	if format == "csv" {
+		mimetype = "text/csv"
+		data, err = encodeBlacklistCSV(entries)
+	} else {
+		data, err = json.MarshalIndent(entries, "", "  ")
+	}
+	if err != nil {
+		fmt.Printf("[ERRO] Exportar lista negra: %v\n", err)
+		sendText(chat, "*[OdinBOT]* Erro ao exportar a lista negra.")
+		return
+	}
+	fileName := fmt.Sprintf("listanegra-%s.%s", now.Format("2006-01-02"), format)
+	caption := fmt.Sprintf("*[OdinBOT]* Lista negra: %d numeros.\nPara importar em outro bot, responda ao arquivo com #importlista.", len(entries))
+	if err := sendDocument(sender.ToNonAD(), data, fileName, mimetype, caption); err != nil {
+		fmt.Printf("[ERRO] Enviar lista negra: %v\n", err)
+		sendText(chat, "*[OdinBOT]* Erro ao enviar o arquivo.")
+		return
+	}
+	if chat.Server == "g.us" {
+		sendText(chat, "*[OdinBOT]* Lista negra enviada no seu privado.")
+	}
+}
+
+// cmdImportBlacklist importa o documento (JSON/CSV) respondido com #importlista.
+func cmdImportBlacklist(chat types.JID, msg *events.Message, sender types.JID) {
+	doc := getContextInfo(msg).GetQuotedMessage().GetDocumentMessage()
+	if doc == nil {
+		sendText(chat, "*[OdinBOT]* Responda a um arquivo JSON/CSV exportado com #exportlista.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	data, err := client.Download(ctx, doc)
+	if err != nil {
+		fmt.Printf("[ERRO] Baixar lista negra: %v\n", err)
+		sendText(chat, "*[OdinBOT]* Nao consegui baixar o arquivo.")
+		return
+	}
+	entries, err := parseBlacklistData(data)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Arquivo invalido: "+err.Error())
+		return
+	}
+	source := "import:" + doc.GetFileName()
+	added, skipped := mergeSharedBlacklist(source, entries)
+	saveBotData()
+	logAction("", sender.User, "", "importlista", fmt.Sprintf("%s: %d adicionados, %d ignorados", source, added, skipped), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Importacao concluida!\nAdicionados: %d\nIgnorados (locais, liberados ou vencidos): %d", added, skipped))
+}
+
+func fetchBlacklistFeed(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+}
+
+// syncBlacklistFeeds baixa todas as fontes configuradas e devolve um resumo por fonte.
+func syncBlacklistFeeds() []string {
+	botData.mu.RLock()
+	feeds := append([]string(nil), botData.BlacklistFeeds...)
+	botData.mu.RUnlock()
+
+	var report []string
+	changed := false
+	for _, src := range feeds {
+		data, err := fetchBlacklistFeed(src)
+		if err == nil {
+			var entries []BlacklistEntry
+			if entries, err = parseBlacklistData(data); err == nil {
+				added, skipped := mergeSharedBlacklist(src, entries)
+				changed = true
+				report = append(report, fmt.Sprintf("%s: %d numeros (%d ignorados)", src, added, skipped))
+				continue
+			}
+		}
+		// Em caso de erro mantemos as entradas da ultima sincronizacao
+		fmt.Printf("[ERRO] Lista compartilhada %s: %v\n", src, err)
+		report = append(report, fmt.Sprintf("%s: erro (%v)", src, err))
+	}
+	if changed {
+		saveBotData()
+	}
+	return report
+}
+
+func blacklistFeedPuller() {
+	for {
+		syncBlacklistFeeds()
+		time.Sleep(6 * time.Hour)
+	}
+}
+
+// cmdBlacklistFeeds: #listafonte [add <url|arquivo> | del <url|arquivo> | sync]
+func cmdBlacklistFeeds(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(args)
+	action := ""
+	if len(parts) > 0 {
+		action = strings.ToLower(parts[0])
+	}
+	switch action {
+	case "add", "del":
+		if len(parts) < 2 {
+			sendText(chat, "*[OdinBOT]* Uso: #listafonte add|del <url ou arquivo>")
+			return
+		}
+		src := parts[1]
+		botData.mu.Lock()
+		if action == "add" {
+			if !containsString(botData.BlacklistFeeds, src) {
+				botData.BlacklistFeeds = append(botData.BlacklistFeeds, src)
+			}
+		} else {
+			kept := botData.BlacklistFeeds[:0]
+			for _, f := range botData.BlacklistFeeds {
+				if f != src {
+					kept = append(kept, f)
+				}
+			}
+			botData.BlacklistFeeds = kept
+		}
+		botData.mu.Unlock()
+		if action == "del" {
+			// Remove tambem os numeros que vieram dessa fonte
+			mergeSharedBlacklist(src, nil)
+		}
+		saveBotData()
+		logAction("", sender.User, "", "listafonte", action+" "+src, SourceCommand)
+		if action == "add" {
+			sendText(chat, "*[OdinBOT]* Fonte adicionada. Sincronizando...\n"+strings.Join(syncBlacklistFeeds(), "\n"))
+		} else {
+			sendText(chat, "*[OdinBOT]* Fonte removida: "+src)
+		}
+	case "sync":
+		report := syncBlacklistFeeds()
+		if len(report) == 0 {
+			sendText(chat, "*[OdinBOT]* Nenhuma fonte configurada.")
+			return
+		}
+		sendText(chat, "*[OdinBOT] Sincronizacao:*\n\n"+strings.Join(report, "\n"))
+	default:
+		botData.mu.RLock()
+		feeds := append([]string(nil), botData.BlacklistFeeds...)
+		overrides := len(botData.BlacklistOverrides)
+		botData.mu.RUnlock()
+		msg := "*[OdinBOT] Listas compartilhadas:*\n\n"
+		if len(feeds) == 0 {
+			msg += "Nenhuma fonte configurada.\n"
+		}
+		for i, f := range feeds {
+			msg += fmt.Sprintf("%d. %s\n", i+1, f)
+		}
+		msg += fmt.Sprintf("\nNumeros liberados localmente: %d\nUso: #listafonte add|del <url ou arquivo> | sync", overrides)
+		sendText(chat, msg)
+	}
+}
+
+// ============================================================
+// Audit Log
+// ============================================================
+
+var auditMu sync.Mutex
+
+// logAction acrescenta uma entrada ao log de auditoria (data/auditlog.jsonl).
+// O arquivo e somente-append: uma linha JSON por acao, lida tambem pelo painel.
+func logAction(group, actor, target, action, reason, source string) {
+	entry := AuditEntry{
+		Time:   time.Now().Format("2006-01-02 15:04:05"),
+		Actor:  actor,
+		Target: target,
+		Group:  group,
+		Action: action,
+		Reason: reason,
+		Source: source,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("[ERRO] Serializar log: %v\n", err)
+		return
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(dataDir, "auditlog.jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("[ERRO] Abrir log: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		fmt.Printf("[ERRO] Gravar log: %v\n", err)
+	}
+}
+
+func readAuditLog() []AuditEntry {
+	auditMu.Lock()
+	file, err := os.ReadFile(filepath.Join(dataDir, "auditlog.jsonl"))
+	auditMu.Unlock()
+	if err != nil {
+		return nil
+	}
+	var entries []AuditEntry
+	for _, line := range strings.Split(string(file), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal([]byte(line), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func cmdLogs(chat types.JID, msg *events.Message, args string, global bool) {
+	limit := 10
+	action := ""
+	user := ""
+	if target := getMentionedJID(msg); target != nil {
+		user = target.User
+	}
+	for _, a := range strings.Fields(args) {
+		if a == "global" {
+			continue
+		}
+		if n, err := strconv.Atoi(a); err == nil {
+			if n > 0 {
+				limit = n
+			}
+			continue
+		}
+		if strings.HasPrefix(a, "@") {
+			if user == "" {
+				user = strings.TrimPrefix(a, "@")
+			}
+			continue
+		}
+		action = strings.ToLower(a)
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	gJID := chat.String()
+	entries := readAuditLog()
+	var found []AuditEntry
+	for i := len(entries) - 1; i >= 0 && len(found) < limit; i-- {
+		e := entries[i]
+		if !global && e.Group != gJID {
+			continue
+		}
+		if user != "" && e.Target != user && e.Actor != user {
+			continue
+		}
+		if action != "" && e.Action != action {
+			continue
+		}
+		found = append(found, e)
+	}
+	if len(found) == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhum registro encontrado.")
+		return
+	}
+
+	title := "Logs do Grupo"
+	if global {
+		title = "Logs Globais"
+	}
+	out := fmt.Sprintf("*[OdinBOT] %s (%d):*\n\n", title, len(found))
+	for _, e := range found {
+		out += fmt.Sprintf("- %s | *%s* | %s", e.Time, e.Action, e.Actor)
+		if e.Target != "" {
+			out += fmt.Sprintf(" -> %s", e.Target)
+		}
+		if e.Reason != "" {
+			out += fmt.Sprintf("\n   %s", e.Reason)
+		}
+		out += fmt.Sprintf(" (%s)", e.Source)
+		if global && e.Group != "" {
+			out += fmt.Sprintf("\n   %s", e.Group)
+		}
+		out += "\n"
+	}
+	sendText(chat, out)
+}
+
+// ============================================================
+// Reports
+// ============================================================
+
+func cmdReport(chat types.JID, msg *events.Message, sender types.JID, reason string) {
+	quoted, ok := getQuotedMessage(msg)
+	if !ok {
+		sendText(chat, "*[OdinBOT]* Responda a mensagem que deseja denunciar com #report [motivo].")
+		return
+	}
+	reported := quoted.Sender
+	if reported.User == sender.User {
+		sendText(chat, "*[OdinBOT]* Voce nao pode denunciar a si mesmo.")
+		return
+	}
+	gJID := chat.String()
+	if reason == "" {
+		reason = "Sem motivo informado"
+	}
+
+	botData.mu.Lock()
+	for _, r := range botData.Reports {
+		if r.GroupJID == gJID && r.MessageID == quoted.ID && r.Status == "aberto" {
+			botData.mu.Unlock()
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* Essa mensagem ja foi denunciada (#%d). Os admins vao analisar.", r.ID))
+			return
+		}
+	}
+	botData.ReportSeq++
+	report := Report{
+		ID:        botData.ReportSeq,
+		GroupJID:  gJID,
+		Reporter:  sender.User,
+		Reported:  reported.User,
+		MessageID: quoted.ID,
+		Text:      quoted.Text,
+		Reason:    reason,
+		Date:      time.Now().Format("2006-01-02 15:04"),
+		Status:    "aberto",
+	}
+	botData.Reports = append(botData.Reports, report)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, reported.User, "report", reason, SourceCommand)
+
+	notifyReportAdmins(chat, report)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Denuncia #%d registrada. Os admins foram avisados.", report.ID))
+}
+
+// notifyReportAdmins envia a denuncia ao grupo de admins configurado ou, se nao houver, no privado de cada admin.
+func notifyReportAdmins(chat types.JID, r Report) {
+	text := r.Text
+	if text == "" {
+		text = "(midia ou mensagem sem texto)"
+	}
+	notice := fmt.Sprintf("*[OdinBOT] Denuncia #%d*\n\nGrupo: %s\nDenunciado: @%s\nPor: @%s\nMotivo: %s\nMensagem: %s\n\nUse #resolver %d ban|advertir|ignorar",
+		r.ID, getGroupName(chat), r.Reported, r.Reporter, r.Reason, text, r.ID)
+
+	cfg := getGroupConfig(r.GroupJID)
+	botData.mu.RLock()
+	reportGroup := cfg.ReportGroup
+	botData.mu.RUnlock()
+	if reportGroup != "" {
+		if jid, err := types.ParseJID(reportGroup); err == nil {
+			sendMention(jid, notice, []string{r.Reported, r.Reporter})
+			return
+		}
+	}
+
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		return
+	}
+	for _, p := range info.Participants {
+		if (p.IsAdmin || p.IsSuperAdmin) && (client.Store.ID == nil || p.JID.User != client.Store.ID.User) {
+			sendText(p.JID, notice)
+		}
+	}
+}
+
+// reportGroupsFor retorna os grupos cujas denuncias podem ser vistas a partir deste chat:
+// o proprio grupo e os grupos que apontam para ele como grupo de admins.
+func reportGroupsFor(chat types.JID) map[string]bool {
+	gJID := chat.String()
+	groups := map[string]bool{gJID: true}
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	for jid, cfg := range botData.Groups {
+		if cfg.ReportGroup == gJID {
+			groups[jid] = true
+		}
+	}
+	return groups
+}
+
+func cmdListReports(chat types.JID) {
+	groups := reportGroupsFor(chat)
+	botData.mu.RLock()
+	msg := "*[OdinBOT] Denuncias abertas:*\n\n"
+	count := 0
+	for _, r := range botData.Reports {
+		if r.Status != "aberto" || !groups[r.GroupJID] {
+			continue
+		}
+		text := r.Text
+		if rs := []rune(text); len(rs) > 80 {
+			text = string(rs[:80]) + "..."
+		}
+		msg += fmt.Sprintf("#%d - %s\n   Denunciado: %s | Por: %s\n   Motivo: %s\n", r.ID, r.Date, r.Reported, r.Reporter, r.Reason)
+		if text != "" {
+			msg += fmt.Sprintf("   Msg: %s\n", text)
+		}
+		if len(groups) > 1 {
+			msg += fmt.Sprintf("   Grupo: %s\n", r.GroupJID)
+		}
+		msg += "\n"
+		count++
+	}
+	botData.mu.RUnlock()
+	if count == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhuma denuncia aberta.")
+		return
+	}
+	sendText(chat, msg+"Use #resolver <id> ban|advertir|ignorar")
+}
+
+func cmdResolveReport(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(strings.ToLower(args))
+	if len(parts) < 2 {
+		sendText(chat, "*[OdinBOT]* Uso: #resolver <id> ban|advertir|ignorar")
+		return
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(parts[0], "#"))
+	action := parts[1]
+	if err != nil || (action != "ban" && action != "advertir" && action != "ignorar") {
+		sendText(chat, "*[OdinBOT]* Uso: #resolver <id> ban|advertir|ignorar")
+		return
+	}
+	groups := reportGroupsFor(chat)
+
+	botData.mu.RLock()
+	var report *Report
+	for i := range botData.Reports {
+		if botData.Reports[i].ID == id {
+			r := botData.Reports[i]
+			report = &r
+			break
+		}
+	}
+	botData.mu.RUnlock()
+	if report == nil || !groups[report.GroupJID] {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Denuncia #%d nao encontrada.", id))
+		return
+	}
+	if report.Status != "aberto" {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Denuncia #%d ja foi resolvida (%s).", id, report.Action))
+		return
+	}
+	group, err := types.ParseJID(report.GroupJID)
+	if err != nil {
+		return
+	}
+	// Permissao e cargo valem no grupo denunciado, nao no grupo de relatorios
+	if action != "ignorar" && !isOwnerNumber(sender.User) && !canRunCommand(group, sender, action) {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Voce nao tem permissao para %s no grupo da denuncia.", action))
+		return
+	}
+	if action != "ignorar" && isOwnerNumber(report.Reported) {
+		sendText(chat, "*[OdinBOT]* Nao posso punir o dono!")
+		return
+	}
+	target := types.NewJID(report.Reported, types.DefaultUserServer)
+	if action != "ignorar" && !outranks(group, sender, target) {
+		sendText(chat, outrankedMsg)
+		return
+	}
+
+	reason := fmt.Sprintf("Denuncia #%d: %s", report.ID, report.Reason)
+	switch action {
+	case "ban":
+		if err := banUser(group, target, sender.User, reason, SourceCommand); err != nil {
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* Nao consegui remover %s; a denuncia #%d continua aberta.", report.Reported, id))
+			return
+		}
+	case "advertir":
+		warnUser(group, target, sender.User, reason, SourceCommand)
+	}
+
+	botData.mu.Lock()
+	for i := range botData.Reports {
+		if botData.Reports[i].ID == id {
+			botData.Reports[i].Status = "resolvido"
+			botData.Reports[i].ResolvedBy = sender.User
+			botData.Reports[i].Action = action
+		}
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(report.GroupJID, sender.User, report.Reported, "resolver", fmt.Sprintf("#%d %s", id, action), SourceCommand)
+	if group != chat || action == "ignorar" {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Denuncia #%d resolvida: %s.", id, action))
+	}
+}
+
+func cmdSetReportGroup(chat types.JID, sender types.JID, args string) {
+	arg := strings.TrimSpace(args)
+	if arg == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #relatoriosgp <jid do grupo de admins|off>\nSem grupo, as denuncias vao no privado dos admins.")
+		return
+	}
+	gJID := chat.String()
+	value := ""
+	if arg != "off" {
+		jid, err := types.ParseJID(arg)
+		if err != nil || jid.Server != types.GroupServer {
+			sendText(chat, "*[OdinBOT]* JID de grupo invalido. Ex: 120363...@g.us")
+			return
+		}
+		value = jid.String()
+	}
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.ReportGroup = value
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "relatoriosgp", arg, SourceCommand)
+	if value == "" {
+		sendText(chat, "*[OdinBOT]* Denuncias serao enviadas no privado dos admins.")
+	} else {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Denuncias serao enviadas para %s.", value))
+	}
+}
+
+// ============================================================
+// Appeals
+// ============================================================
+
+const (
+	appealCooldown = 24 * time.Hour
+	appealMaxText  = 500
+)
+
+// cmdAppeal: #apelar <texto>, no privado, por quem esta na lista negra.
+// Uma apelacao aberta por vez e no maximo uma a cada 24h.
+func cmdAppeal(chat types.JID, sender types.JID, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		sendText(chat, "*[OdinBOT]* Uso: #apelar <explique por que deve sair da lista negra>")
+		return
+	}
+	if r := []rune(text); len(r) > appealMaxText {
+		text = string(r[:appealMaxText])
+	}
+
+	now := time.Now()
+	var keys, groups []string
+	toOwner := false
+	botData.mu.Lock()
+	for _, a := range botData.Appeals {
+		if a.Number != sender.User {
+			continue
+		}
+		if a.Status == "aberto" {
+			botData.mu.Unlock()
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* Sua apelacao #%d ainda esta em analise. Aguarde.", a.ID))
+			return
+		}
+		if at, err := time.ParseInLocation("2006-01-02 15:04", a.Date, time.Local); err == nil && now.Sub(at) < appealCooldown {
+			botData.mu.Unlock()
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* Voce ja apelou recentemente. Tente de novo em %d horas.", int((appealCooldown-now.Sub(at)).Hours())+1))
+			return
+		}
+	}
+	for key, b := range botData.Blacklist {
+		if (b.Number != sender.User && key != sender.User) || blacklistExpired(b, now) {
+			continue
+		}
+		keys = append(keys, key)
+		if b.Scope == ScopeGroup {
+			if !containsString(groups, b.GroupJID) {
+				groups = append(groups, b.GroupJID)
+			}
+		} else {
+			toOwner = true
+		}
+	}
+	if len(keys) == 0 {
+		botData.mu.Unlock()
+		sendText(chat, "*[OdinBOT]* Seu numero nao esta na lista negra.")
+		return
+	}
+	botData.AppealSeq++
+	appeal := Appeal{
+		ID:     botData.AppealSeq,
+		Number: sender.User,
+		Text:   text,
+		Date:   now.Format("2006-01-02 15:04"),
+		Keys:   keys,
+		Groups: groups,
+		Status: "aberto",
+	}
+	botData.Appeals = append(botData.Appeals, appeal)
+	botData.mu.Unlock()
+	saveBotData()
+	logAction("", sender.User, sender.User, "apelar", text, SourceCommand)
+
+	notice := fmt.Sprintf("*[OdinBOT] Apelacao #%d*\n\nNumero: wa.me/%s\nTexto: %s\n\nUse #aceitarapelo %d ou #negarapelo %d [motivo]",
+		appeal.ID, appeal.Number, appeal.Text, appeal.ID, appeal.ID)
+	// Entradas globais ou do dono so o dono do bot pode tirar
+	if toOwner {
+		sendText(types.NewJID(OwnerNumber, types.DefaultUserServer), notice)
+	} else {
+		for _, g := range groups {
+			if jid, err := types.ParseJID(g); err == nil {
+				notifyGroupAdmins(g, notice+"\nGrupo: "+getGroupName(jid))
+			}
+		}
+	}
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Apelacao #%d enviada. Voce sera avisado da decisao.", appeal.ID))
+}
+
+// notifyGroupAdmins avisa o grupo de admins configurado (#relatoriosgp) ou cada admin no privado.
+func notifyGroupAdmins(groupJID string, notice string) {
+	cfg := getGroupConfig(groupJID)
+	botData.mu.RLock()
+	reportGroup := cfg.ReportGroup
+	botData.mu.RUnlock()
+	if reportGroup != "" {
+		if jid, err := types.ParseJID(reportGroup); err == nil {
+			sendText(jid, notice)
+			return
+		}
+	}
+	chat, err := types.ParseJID(groupJID)
+	if err != nil {
+		return
+	}
+	for _, admin := range groupAdminJIDs(chat) {
+		sendText(admin, notice)
+	}
+}
+
+// appealVisible: o dono ve todas; admins so as que envolvem apenas grupos visiveis daqui.
+func appealVisible(a Appeal, groups map[string]bool, isOwner bool) bool {
+	if isOwner {
+		return true
+	}
+	if len(a.Groups) == 0 || len(a.Groups) != len(a.Keys) {
+		return false
+	}
+	for _, g := range a.Groups {
+		if !groups[g] {
+			return false
+		}
+	}
+	return true
+}
+
+func cmdListAppeals(chat types.JID, isOwner bool) {
+	groups := reportGroupsFor(chat)
+	botData.mu.RLock()
+	msg := "*[OdinBOT] Apelacoes abertas:*\n\n"
+	count := 0
+	for _, a := range botData.Appeals {
+		if a.Status != "aberto" || !appealVisible(a, groups, isOwner) {
+			continue
+		}
+		msg += fmt.Sprintf("#%d - %s\n   Numero: %s\n   Texto: %s\n\n", a.ID, a.Date, a.Number, a.Text)
+		count++
+	}
+	botData.mu.RUnlock()
+	if count == 0 {
+		sendText(chat, "*[OdinBOT]* Nenhuma apelacao aberta.")
+		return
+	}
+	sendText(chat, msg+"Use #aceitarapelo <id> ou #negarapelo <id> [motivo]")
+}
+
+// cmdResolveAppeal aceita (tira da lista negra) ou nega a apelacao e avisa o usuario.
+func cmdResolveAppeal(chat types.JID, sender types.JID, accept bool, args string, isOwner bool) {
+	parts := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	id, err := strconv.Atoi(strings.TrimPrefix(parts[0], "#"))
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Uso: #aceitarapelo <id> | #negarapelo <id> [motivo]")
+		return
+	}
+	reason := ""
+	if len(parts) > 1 {
+		reason = strings.TrimSpace(parts[1])
+	}
+	groups := reportGroupsFor(chat)
+
+	botData.mu.Lock()
+	var appeal *Appeal
+	for i := range botData.Appeals {
+		if botData.Appeals[i].ID == id {
+			appeal = &botData.Appeals[i]
+			break
+		}
+	}
+	if appeal == nil || !appealVisible(*appeal, groups, isOwner) {
+		botData.mu.Unlock()
+		sendText(chat, "*[OdinBOT]* Apelacao nao encontrada.")
+		return
+	}
+	if appeal.Status != "aberto" {
+		botData.mu.Unlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* A apelacao #%d ja foi %s.", id, appeal.Status))
+		return
+	}
+	appeal.ResolvedBy = sender.User
+	appeal.Status = "negado"
+	if accept {
+		appeal.Status = "aceito"
+		for _, key := range appeal.Keys {
+			if b, ok := botData.Blacklist[key]; ok {
+				// Entrada de lista compartilhada: sem isso a proxima sincronizacao a traria de volta
+				if b.Source != "" && !containsString(botData.BlacklistOverrides, b.Number) {
+					botData.BlacklistOverrides = append(botData.BlacklistOverrides, b.Number)
+				}
+				delete(botData.Blacklist, key)
+			}
+		}
+	}
+	a := *appeal
+	botData.mu.Unlock()
+	saveBotData()
+
+	user := types.NewJID(a.Number, types.DefaultUserServer)
+	if accept {
+		logAction(strings.Join(a.Groups, ","), sender.User, a.Number, "tirardalista", fmt.Sprintf("Apelacao #%d aceita", id), SourceCommand)
+		sendText(user, fmt.Sprintf("*[OdinBOT]* Sua apelacao #%d foi aceita! Voce saiu da lista negra.", id))
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Apelacao #%d aceita. %s saiu da lista negra.", id, a.Number))
+		return
+	}
+	logAction(strings.Join(a.Groups, ","), sender.User, a.Number, "negarapelo", reason, SourceCommand)
+	notice := fmt.Sprintf("*[OdinBOT]* Sua apelacao #%d foi negada.", id)
+	if reason != "" {
+		notice += "\nMotivo: " + reason
+	}
+	sendText(user, notice+fmt.Sprintf("\nVoce pode apelar de novo em %d horas.", int(appealCooldown.Hours())))
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Apelacao #%d negada.", id))
+}
+
+// ============================================================
+// Vote Kick
+// ============================================================
+
+const (
+	defaultVoteKickQuorum  = 5
+	defaultVoteKickPercent = 60
+	defaultVoteKickMinutes = 5
+	voteKickCooldown       = 10 * time.Minute
+)
+
+type voteKick struct {
+	Group   types.JID
+	Target  string
+	Starter string
+	Yes     map[string]bool
+	No      map[string]bool
+	Quorum  int
+	Percent int
+}
+
+var (
+	voteKicksMu    sync.Mutex
+	voteKicks      = make(map[types.MessageID]*voteKick) // ID da mensagem de votacao -> votacao
+	voteKickLastBy = make(map[string]time.Time)          // grupo|usuario -> ultima votacao iniciada/recebida
+)
+
+func voteKickSettings(groupJID string) (quorum, percent, minutes int) {
+	cfg := getGroupConfig(groupJID)
+	botData.mu.RLock()
+	quorum, percent, minutes = cfg.VoteKickQuorum, cfg.VoteKickPercent, cfg.VoteKickMinutes
+	botData.mu.RUnlock()
+	if quorum <= 0 {
+		quorum = defaultVoteKickQuorum
+	}
+	if percent <= 0 {
+		percent = defaultVoteKickPercent
+	}
+	if minutes <= 0 {
+		minutes = defaultVoteKickMinutes
+	}
+	return
+}
+
+func cmdVoteKick(chat types.JID, msg *events.Message, sender types.JID) {
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	enabled := cfg.VoteKick
+	botData.mu.RUnlock()
+	if !enabled {
+		sendText(chat, "*[OdinBOT]* Votekick desativado neste grupo. Admins podem ativar com #votekickcfg on")
+		return
+	}
+	target := getMentionedJID(msg)
+	if target == nil {
+		sendText(chat, "*[OdinBOT]* Uso: #votekick @usuario")
+		return
+	}
+	if target.User == sender.User {
+		sendText(chat, "*[OdinBOT]* Voce nao pode votar para remover a si mesmo.")
+		return
+	}
+	if isOwnerNumber(target.User) || isGroupAdmin(chat, *target) || (client.Store.ID != nil && target.User == client.Store.ID.User) {
+		sendText(chat, "*[OdinBOT]* Nao e possivel abrir votacao contra admins, o dono ou o bot.")
+		return
+	}
+	if !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin para remover membros.")
+		return
+	}
+
+	gJID := chat.String()
+	starterKey := gJID + "|" + sender.User
+	targetKey := gJID + "|" + target.User
+	voteKicksMu.Lock()
+	for _, v := range voteKicks {
+		if v.Group == chat && v.Target == target.User {
+			voteKicksMu.Unlock()
+			sendText(chat, fmt.Sprintf("*[OdinBOT]* Ja existe uma votacao aberta contra @%s.", target.User))
+			return
+		}
+	}
+	if last, ok := voteKickLastBy[starterKey]; ok && time.Since(last) < voteKickCooldown {
+		voteKicksMu.Unlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Aguarde %d minuto(s) para abrir outra votacao.", int((voteKickCooldown-time.Since(last)).Minutes())+1))
+		return
+	}
+	if last, ok := voteKickLastBy[targetKey]; ok && time.Since(last) < voteKickCooldown {
+		voteKicksMu.Unlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s passou por uma votacao recentemente. Aguarde.", target.User))
+		return
+	}
+	voteKicksMu.Unlock()
+
+	quorum, percent, minutes := voteKickSettings(gJID)
+	id := sendMentionGetID(chat, fmt.Sprintf("*[OdinBOT] VOTEKICK*\n\n@%s abriu votacao para remover @%s.\n\nReaja a ESTA mensagem com 👍 (sim) ou 👎 (nao).\nMinimo de %d votos e %d%% de sim.\nEncerra em %d minuto(s).",
+		sender.User, target.User, quorum, percent, minutes), []string{sender.User, target.User})
+	if id == "" {
+		return
+	}
+
+	// O intervalo so conta depois que a votacao foi de fato enviada
+	voteKicksMu.Lock()
+	voteKickLastBy[starterKey] = time.Now()
+	voteKickLastBy[targetKey] = time.Now()
+	voteKicks[id] = &voteKick{
+		Group:   chat,
+		Target:  target.User,
+		Starter: sender.User,
+		Yes:     map[string]bool{sender.User: true},
+		No:      make(map[string]bool),
+		Quorum:  quorum,
+		Percent: percent,
+	}
+	voteKicksMu.Unlock()
+	logAction(gJID, sender.User, target.User, "votekick", "Votacao aberta", SourceCommand)
+	time.AfterFunc(time.Duration(minutes)*time.Minute, func() { finishVoteKick(id) })
+}
+
+// registerVoteKickReaction contabiliza a reacao se ela for em uma votacao aberta.
+func registerVoteKickReaction(chat types.JID, voter types.JID, msgID types.MessageID, emoji string) bool {
+	voteKicksMu.Lock()
+	defer voteKicksMu.Unlock()
+	v, ok := voteKicks[msgID]
+	if !ok || v.Group != chat {
+		return false
+	}
+	if voter.User == v.Target {
+		return true
+	}
+	delete(v.Yes, voter.User)
+	delete(v.No, voter.User)
+	switch {
+	case strings.HasPrefix(emoji, "👍"):
+		v.Yes[voter.User] = true
+	case strings.HasPrefix(emoji, "👎"):
+		v.No[voter.User] = true
+	}
+	return true
+}
+
+func finishVoteKick(id types.MessageID) {
+	voteKicksMu.Lock()
+	v, ok := voteKicks[id]
+	delete(voteKicks, id)
+	voteKicksMu.Unlock()
+	if !ok {
+		return
+	}
+	yes, no := len(v.Yes), len(v.No)
+	total := yes + no
+	gJID := v.Group.String()
+	if total < v.Quorum || yes*100 < v.Percent*total {
+		sendMention(v.Group, fmt.Sprintf("*[OdinBOT]* Votacao contra @%s encerrada: %d sim, %d nao. @%s continua no grupo.", v.Target, yes, no, v.Target), []string{v.Target})
+		logAction(gJID, BotName, v.Target, "votekick", fmt.Sprintf("Rejeitada (%d sim / %d nao)", yes, no), SourceAuto)
+		return
+	}
+
+	target := types.NewJID(v.Target, types.DefaultUserServer)
+	// Durante a votacao o alvo pode ter virado admin ou recebido cargo
+	if isModerationExempt(v.Group, target) {
+		sendMention(v.Group, fmt.Sprintf("*[OdinBOT]* Votacao contra @%s anulada: agora e admin ou tem cargo.", v.Target), []string{v.Target})
+		logAction(gJID, BotName, v.Target, "votekick", fmt.Sprintf("Anulada, alvo isento (%d sim / %d nao)", yes, no), SourceAuto)
+		return
+	}
+	removeMember(v.Group, target)
+	reason := fmt.Sprintf("Removido por votacao (%d sim / %d nao)", yes, no)
+	botData.mu.Lock()
+	botData.Warnings[gJID] = append(botData.Warnings[gJID], Warning{
+		GroupJID: gJID,
+		UserJID:  v.Target,
+		UserName: v.Target,
+		Reason:   reason,
+		Date:     time.Now().Format("2006-01-02 15:04"),
+		IssuedBy: "votekick",
+	})
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, v.Starter, v.Target, "votekick", reason, SourceAuto)
+	sendMention(v.Group, fmt.Sprintf("*[OdinBOT]* Votacao aprovada: %d sim, %d nao. @%s foi removido.", yes, no, v.Target), []string{v.Target})
+}
+
+func cmdVoteKickConfig(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(strings.ToLower(args))
+	if len(parts) == 0 {
+		quorum, percent, minutes := voteKickSettings(gJID)
+		botData.mu.RLock()
+		status := "OFF"
+		if cfg.VoteKick {
+			status = "ON"
+		}
+		botData.mu.RUnlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Votekick:*\n\n- Status: %s\n- Quorum: %d votos\n- Aprovacao: %d%%\n- Duracao: %d min\n\nUso: #votekickcfg on|off | <quorum> [porcentagem] [minutos]", status, quorum, percent, minutes))
+		return
+	}
+	if parts[0] == "on" || parts[0] == "off" {
+		botData.mu.Lock()
+		cfg.VoteKick = parts[0] == "on"
+		botData.mu.Unlock()
+		saveBotData()
+		status := "ativado"
+		if parts[0] == "off" {
+			status = "desativado"
+		}
+		logAction(gJID, sender.User, "", "config", "votekick "+status, SourceCommand)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Votekick %s!", status))
+		return
+	}
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSuffix(p, "%"))
+		if err != nil || n <= 0 || (i == 1 && n > 100) {
+			sendText(chat, "*[OdinBOT]* Uso: #votekickcfg <quorum> [porcentagem 1-100] [minutos]")
+			return
+		}
+		values[i] = n
+	}
+	botData.mu.Lock()
+	cfg.VoteKickQuorum = values[0]
+	if len(values) > 1 {
+		cfg.VoteKickPercent = values[1]
+	}
+	if len(values) > 2 {
+		cfg.VoteKickMinutes = values[2]
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	quorum, percent, minutes := voteKickSettings(gJID)
+	logAction(gJID, sender.User, "", "config", fmt.Sprintf("votekick quorum=%d %d%% %dmin", quorum, percent, minutes), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Votekick: %d votos, %d%% de sim, %d min.", quorum, percent, minutes))
+}
+
+// ============================================================
+// Group Schedule (modo noturno)
+// ============================================================
+
+const defaultScheduleTimezone = "America/Manaus"
+
+var weekdayNames = []string{"dom", "seg", "ter", "qua", "qui", "sex", "sab"}
+
+// scheduleLocation carrega o fuso do horario. Com o tzdata embutido so falha para
+// nomes invalidos (o #sethorario ja recusa), e nesse caso o horario nao e aplicado.
+func scheduleLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = defaultScheduleTimezone
+	}
+	return time.LoadLocation(name)
+}
+
+func parseClock(s string) (int, int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, false
+	}
+	return t.Hour(), t.Minute(), true
+}
+
+// parseWeekdays aceita "todos", listas ("seg,qua,sex") e intervalos ("seg-sex").
+func parseWeekdays(arg string) ([]int, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" || arg == "todos" {
+		return nil, true
+	}
+	index := func(name string) int {
+		for i, n := range weekdayNames {
+			if n == name {
+				return i
+			}
+		}
+		return -1
+	}
+	seen := make(map[int]bool)
+	var days []int
+	for _, part := range strings.Split(arg, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		start := index(bounds[0])
+		end := start
+		if len(bounds) == 2 {
+			end = index(bounds[1])
+		}
+		if start < 0 || end < 0 {
+			return nil, false
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			if !seen[d] {
+				seen[d] = true
+				days = append(days, d)
+			}
+			if d == end {
+				break
+			}
+		}
+	}
+	return days, true
+}
+
+func formatWeekdays(days []int) string {
+	if len(days) == 0 {
+		return "todos os dias"
+	}
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = weekdayNames[d]
+	}
+	return strings.Join(names, ", ")
+}
+
+// scheduleState calcula se o grupo deveria estar fechado agora: procura o ultimo
+// fechamento programado e verifica se a abertura seguinte ainda nao chegou.
+func scheduleState(sch *GroupSchedule, now time.Time) string {
+	closeH, closeM, ok1 := parseClock(sch.Close)
+	openH, openM, ok2 := parseClock(sch.Open)
+	if !ok1 || !ok2 {
+		return ""
+	}
+	loc, err := scheduleLocation(sch.Timezone)
+	if err != nil {
+		return ""
+	}
+	now = now.In(loc)
+	allowed := func(d time.Weekday) bool {
+		if len(sch.Days) == 0 {
+			return true
+		}
+		for _, day := range sch.Days {
+			if day == int(d) {
+				return true
+			}
+		}
+		return false
+	}
+	for back := 0; back <= 7; back++ {
+		day := now.AddDate(0, 0, -back)
+		closeAt := time.Date(day.Year(), day.Month(), day.Day(), closeH, closeM, 0, 0, now.Location())
+		if closeAt.After(now) || !allowed(closeAt.Weekday()) {
+			continue
+		}
+		openAt := time.Date(day.Year(), day.Month(), day.Day(), openH, openM, 0, 0, now.Location())
+		if !openAt.After(closeAt) {
+			openAt = openAt.AddDate(0, 0, 1)
+		}
+		if now.Before(openAt) {
+			return "fechado"
+		}
+		return "aberto"
+	}
+	return "aberto"
+}
+
+func applyGroupSchedule(groupJID string, sch GroupSchedule, state string) bool {
+	chat, err := types.ParseJID(groupJID)
+	if err != nil || !isBotAdmin(chat) {
+		return false
+	}
+	if err := client.SetGroupAnnounce(context.Background(), chat, state == "fechado"); err != nil {
+		fmt.Printf("[ERRO] Horario do grupo %s: %v\n", groupJID, err)
+		return false
+	}
+	action, notice := "abrirgp", sch.OpenMsg
+	if state == "fechado" {
+		action, notice = "fechargp", sch.CloseMsg
+	}
+	logAction(groupJID, BotName, "", action, "Horario programado", SourceAuto)
+	if notice != "" {
+		sendText(chat, "*[OdinBOT]* "+notice)
+	}
+	return true
+}
+
+// groupScheduler aplica os horarios a cada minuto. Como compara o estado desejado
+// com o ultimo aplicado, tambem recupera trocas perdidas enquanto o bot estava offline.
+func groupScheduler() {
+	for {
+		now := time.Now()
+		type pending struct {
+			jid   string
+			sch   GroupSchedule
+			state string
+		}
+		var todo []pending
+		botData.mu.RLock()
+		for jid, cfg := range botData.Groups {
+			if cfg.Schedule == nil {
+				continue
+			}
+			state := scheduleState(cfg.Schedule, now)
+			if state != "" && state != cfg.Schedule.LastState {
+				todo = append(todo, pending{jid, *cfg.Schedule, state})
+			}
+		}
+		botData.mu.RUnlock()
+
+		changed := false
+		for _, p := range todo {
+			if !applyGroupSchedule(p.jid, p.sch, p.state) {
+				continue
+			}
+			botData.mu.Lock()
+			if cfg, ok := botData.Groups[p.jid]; ok && cfg.Schedule != nil {
+				cfg.Schedule.LastState = p.state
+				changed = true
+			}
+			botData.mu.Unlock()
+		}
+		if changed {
+			saveBotData()
+		}
+		time.Sleep(1 * time.Minute)
+	}
+}
+
+func cmdShowSchedule(chat types.JID) {
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	if cfg.Schedule == nil {
+		botData.mu.RUnlock()
+		sendText(chat, "*[OdinBOT]* Este grupo nao tem horario programado.\nAdmins: #sethorario 23:00 07:00 [dias] [fuso]")
+		return
+	}
+	sch := *cfg.Schedule
+	botData.mu.RUnlock()
+	tz := sch.Timezone
+	if tz == "" {
+		tz = defaultScheduleTimezone
+	}
+	state := scheduleState(&sch, time.Now())
+	msg := fmt.Sprintf(`*[OdinBOT] Horario do Grupo:*
+
+- Fecha: %s
+- Abre: %s
+- Dias: %s
+- Fuso: %s
+- Agora: %s`, sch.Close, sch.Open, formatWeekdays(sch.Days), tz, state)
+	if sch.CloseMsg != "" {
+		msg += "\n- Aviso ao fechar: " + sch.CloseMsg
+	}
+	if sch.OpenMsg != "" {
+		msg += "\n- Aviso ao abrir: " + sch.OpenMsg
+	}
+	sendText(chat, msg)
+}
+
+func cmdSetSchedule(chat types.JID, sender types.JID, args string) {
+	if settingsLocked(chat, sender) {
+		return
+	}
+	gJID := chat.String()
+	parts := strings.Fields(args)
+	if len(parts) == 1 && strings.ToLower(parts[0]) == "off" {
+		cfg := getGroupConfig(gJID)
+		botData.mu.Lock()
+		cfg.Schedule = nil
+		botData.mu.Unlock()
+		saveBotData()
+		logAction(gJID, sender.User, "", "config", "horario off", SourceCommand)
+		sendText(chat, "*[OdinBOT]* Horario automatico desativado.")
+		return
+	}
+	usage := "*[OdinBOT]* Uso: #sethorario <fechar HH:MM> <abrir HH:MM> [dias] [fuso]\nEx: #sethorario 23:00 07:00 seg-sex America/Manaus\nDias: todos, dom, seg, ter, qua, qui, sex, sab (listas com virgula ou intervalos)\nDesativar: #sethorario off"
+	if len(parts) < 2 {
+		sendText(chat, usage)
+		return
+	}
+	_, _, ok1 := parseClock(parts[0])
+	_, _, ok2 := parseClock(parts[1])
+	if !ok1 || !ok2 || parts[0] == parts[1] {
+		sendText(chat, usage)
+		return
+	}
+	var days []int
+	if len(parts) > 2 {
+		d, ok := parseWeekdays(parts[2])
+		if !ok {
+			sendText(chat, usage)
+			return
+		}
+		days = d
+	}
+	tz := defaultScheduleTimezone
+	if len(parts) > 3 {
+		if _, err := time.LoadLocation(parts[3]); err != nil {
+			sendText(chat, "*[OdinBOT]* Fuso horario invalido. Ex: America/Manaus, America/Sao_Paulo")
+			return
+		}
+		tz = parts[3]
+	}
+
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	sch := &GroupSchedule{Close: parts[0], Open: parts[1], Days: days, Timezone: tz}
+	if cfg.Schedule != nil {
+		sch.CloseMsg, sch.OpenMsg = cfg.Schedule.CloseMsg, cfg.Schedule.OpenMsg
+	}
+	// O estado atual e considerado aplicado; so as proximas trocas agem no grupo
+	sch.LastState = scheduleState(sch, time.Now())
+	cfg.Schedule = sch
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "config", fmt.Sprintf("horario fecha %s abre %s (%s)", sch.Close, sch.Open, formatWeekdays(days)), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Horario definido! Fecha as %s e abre as %s (%s, %s).", sch.Close, sch.Open, formatWeekdays(days), tz))
+}
+
+func cmdScheduleMessage(chat types.JID, sender types.JID, args string) {
+	parts := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	kind := strings.ToLower(parts[0])
+	if len(parts) < 2 || (kind != "fechar" && kind != "abrir") {
+		sendText(chat, "*[OdinBOT]* Uso: #horariomsg fechar|abrir <texto|off>")
+		return
+	}
+	text := strings.TrimSpace(parts[1])
+	if strings.ToLower(text) == "off" {
+		text = ""
+	}
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	if cfg.Schedule == nil {
+		botData.mu.Unlock()
+		sendText(chat, "*[OdinBOT]* Defina o horario primeiro com #sethorario.")
+		return
+	}
+	if kind == "fechar" {
+		cfg.Schedule.CloseMsg = text
+	} else {
+		cfg.Schedule.OpenMsg = text
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "config", "horariomsg "+kind, SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Aviso ao %s atualizado!", kind))
+}
+
+// ============================================================
+// Settings Lock (anti-tamper)
+// ============================================================
+
+// isSettingsAuthorized: com a config travada, so o dono, o proprio bot e quem tem
+// cargo com permissao de config pode mudar o grupo. Ser admin do WhatsApp nao basta.
+func isSettingsAuthorized(chat types.JID, user types.JID) bool {
+	if isOwnerNumber(user.User) || (client.Store.ID != nil && user.User == client.Store.ID.User) {
+		return true
+	}
+	for _, p := range rolePermissions[getRole(chat.String(), user.User)] {
+		if p == PermConfig {
+			return true
+		}
+	}
+	return false
+}
+
+// settingsLocked avisa e retorna true se o grupo tem config travada e o usuario nao
+// pode mexer nela. Sem isso um admin do WhatsApp mudaria o travado pelo proprio bot.
+func settingsLocked(chat types.JID, user types.JID) bool {
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	locked := cfg.Lock != nil
+	botData.mu.RUnlock()
+	if !locked || isSettingsAuthorized(chat, user) {
+		return false
+	}
+	sendText(chat, "*[OdinBOT]* As configuracoes deste grupo estao travadas. So o dono ou cargos com permissao de config podem altera-las.")
+	return true
+}
+
+func cmdLockSettings(chat types.JID, sender types.JID, args string) {
+	// Re-travar grava o estado atual como aprovado: mesma regra de quem altera
+	if settingsLocked(chat, sender) {
+		return
+	}
+	info, err := client.GetGroupInfo(context.Background(), chat)
+	if err != nil {
+		sendText(chat, "*[OdinBOT]* Erro ao obter dados do grupo.")
+		return
+	}
+	lock := &GroupLock{
+		Name:     info.Name,
+		Topic:    info.Topic,
+		Announce: info.IsAnnounce,
+		Locked:   info.IsLocked,
+		Demote:   strings.ToLower(strings.TrimSpace(args)) == "rebaixar",
+	}
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.Lock = lock
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "travarconfig", "", SourceCommand)
+	msg := "*[OdinBOT]* Configuracoes travadas! Mudancas de nome, descricao e permissoes feitas por admins sem cargo serao desfeitas."
+	if lock.Demote {
+		msg += "\nQuem alterar tambem perde o admin."
+	}
+	if !isBotAdmin(chat) {
+		msg += "\nAtencao: preciso ser admin para reverter alteracoes."
+	}
+	sendText(chat, msg)
+}
+
+func cmdUnlockSettings(chat types.JID, sender types.JID) {
+	if settingsLocked(chat, sender) {
+		return
+	}
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.Lock()
+	cfg.Lock = nil
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "destravarconfig", "", SourceCommand)
+	sendText(chat, "*[OdinBOT]* Configuracoes destravadas.")
+}
+
+// checkGroupTamper compara a mudanca com a configuracao aprovada. Mudancas de quem
+// tem autorizacao (inclusive do bot, via #nomegp, #fechargp, horario...) passam a
+// ser a nova configuracao aprovada; as demais sao revertidas.
+func checkGroupTamper(evt *events.GroupInfo) {
+	groupJID := evt.JID.String()
+	cfg := getGroupConfig(groupJID)
+	botData.mu.RLock()
+	if cfg.Lock == nil || evt.Sender == nil {
+		botData.mu.RUnlock()
+		return
+	}
+	lock := *cfg.Lock
+	botData.mu.RUnlock()
+	actor := *evt.Sender
+
+	if isSettingsAuthorized(evt.JID, actor) {
+		botData.mu.Lock()
+		if cfg.Lock != nil {
+			if evt.Name != nil {
+				cfg.Lock.Name = evt.Name.Name
+			}
+			if evt.Topic != nil {
+				cfg.Lock.Topic = evt.Topic.Topic
+			}
+			if evt.Announce != nil {
+				cfg.Lock.Announce = evt.Announce.IsAnnounce
+			}
+			if evt.Locked != nil {
+				cfg.Lock.Locked = evt.Locked.IsLocked
+			}
+		}
+		botData.mu.Unlock()
+		saveBotData()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var changes []string
+	revert := func(what string, err error) {
+		changes = append(changes, what)
+		if err != nil {
+			fmt.Printf("[ERRO] Reverter %s em %s: %v\n", what, groupJID, err)
+		}
+	}
+	if !isBotAdmin(evt.JID) {
+		sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s alterou as configuracoes travadas, mas preciso ser admin para desfazer.", actor.User), []string{actor.User})
+		return
+	}
+	if evt.Name != nil && evt.Name.Name != lock.Name {
+		revert("nome", client.SetGroupName(ctx, evt.JID, lock.Name))
+	}
+	if evt.Topic != nil && evt.Topic.Topic != lock.Topic {
+		revert("descricao", client.SetGroupTopic(ctx, evt.JID, "", "", lock.Topic))
+	}
+	if evt.Announce != nil && evt.Announce.IsAnnounce != lock.Announce {
+		revert("envio de mensagens", client.SetGroupAnnounce(ctx, evt.JID, lock.Announce))
+	}
+	if evt.Locked != nil && evt.Locked.IsLocked != lock.Locked {
+		revert("edicao de dados", client.SetGroupLocked(ctx, evt.JID, lock.Locked))
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	what := strings.Join(changes, ", ")
+	logAction(groupJID, BotName, actor.User, "reverter", "Alterou sem autorizacao: "+what, SourceAuto)
+	msg := fmt.Sprintf("*[OdinBOT]* @%s alterou %s sem autorizacao. Alteracao desfeita, as configuracoes deste grupo estao travadas.", actor.User, what)
+	if lock.Demote && !isOwnerNumber(actor.User) {
+		if _, err := client.UpdateGroupParticipants(ctx, evt.JID, []types.JID{actor}, whatsmeow.ParticipantChangeDemote); err != nil {
+			fmt.Printf("[ERRO] Rebaixar %s: %v\n", actor.User, err)
+		} else {
+			logAction(groupJID, BotName, actor.User, "rebaixar", "Alterou configuracoes travadas", SourceAuto)
+			msg += "\nAdmin removido."
+		}
+	}
+	sendMention(evt.JID, msg, []string{actor.User})
+}
+
+// ============================================================
+// Admin Changes
+// ============================================================
+
+// isProtectedAdmin: o dono do bot e sempre protegido, alem da lista do grupo.
+func isProtectedAdmin(groupJID string, user string) bool {
+	if isOwnerNumber(user) {
+		return true
+	}
+	cfg := getGroupConfig(groupJID)
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	return containsString(cfg.ProtectedAdmins, user)
+}
+
+// cmdProtectAdmin: #protegeradm @user alterna a protecao; sem mencao lista os protegidos.
+// Qualquer admin com PermManage protege, mas so o dono ou o proprio protegido desprotege.
+func cmdProtectAdmin(chat types.JID, msg *events.Message, sender types.JID) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	target := getMentionedJID(msg)
+	if target == nil {
+		botData.mu.RLock()
+		list := append([]string(nil), cfg.ProtectedAdmins...)
+		botData.mu.RUnlock()
+		text := "*[OdinBOT] Admins protegidos:*\n\n- " + OwnerName + " (dono)\n"
+		for _, u := range list {
+			text += "- @" + u + "\n"
+		}
+		text += "\nUse #protegeradm @user para adicionar/remover (remover: so o dono ou o proprio admin)."
+		sendMention(chat, text, list)
+		return
+	}
+	botData.mu.Lock()
+	protected := !containsString(cfg.ProtectedAdmins, target.User)
+	// Tirar a protecao: so o dono ou o proprio admin, senao basta desproteger e rebaixar
+	if !protected && !isOwnerNumber(sender.User) && sender.User != target.User {
+		botData.mu.Unlock()
+		sendText(chat, "*[OdinBOT]* So o dono do bot ou o proprio admin pode remover a protecao.")
+		return
+	}
+	if protected {
+		cfg.ProtectedAdmins = append(cfg.ProtectedAdmins, target.User)
+	} else {
+		kept := cfg.ProtectedAdmins[:0]
+		for _, u := range cfg.ProtectedAdmins {
+			if u != target.User {
+				kept = append(kept, u)
+			}
+		}
+		cfg.ProtectedAdmins = kept
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	if protected {
+		logAction(gJID, sender.User, target.User, "protegeradm", "", SourceCommand)
+		sendMention(chat, fmt.Sprintf("*[OdinBOT]* @%s agora e um admin protegido.", target.User), []string{target.User})
+	} else {
+		logAction(gJID, sender.User, target.User, "desprotegeradm", "", SourceCommand)
+		sendMention(chat, fmt.Sprintf("*[OdinBOT]* @%s nao e mais protegido.", target.User), []string{target.User})
+	}
+}
+
+// handleAdminChanges registra promocoes e rebaixamentos feitos pelo WhatsApp.
+// Mudancas feitas pelo proprio bot ja foram registradas pelo comando que as fez.
+// Se um admin protegido for rebaixado por outra pessoa, o bot devolve o admin e
+// rebaixa o responsavel.
+func handleAdminChanges(evt *events.GroupInfo) {
+	groupJID := evt.JID.String()
+	cfg := getGroupConfig(groupJID)
+	botData.mu.RLock()
+	announce := cfg.AnnounceAdmins
+	botData.mu.RUnlock()
+
+	actor := ""
+	if evt.Sender != nil {
+		actor = evt.Sender.User
+	}
+	if client.Store.ID != nil && actor == client.Store.ID.User {
+		return
+	}
+	by := ""
+	mentions := func(user string) []string { return []string{user} }
+	if actor != "" {
+		by = " por @" + actor
+		mentions = func(user string) []string { return []string{user, actor} }
+	}
+
+	for _, jid := range evt.Promote {
+		logAction(groupJID, actor, jid.User, "promover", "Pelo WhatsApp", SourceAuto)
+		if announce {
+			sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s foi promovido a admin%s.", jid.User, by), mentions(jid.User))
+		}
+	}
+
+	for _, jid := range evt.Demote {
+		logAction(groupJID, actor, jid.User, "rebaixar", "Pelo WhatsApp", SourceAuto)
+		isBot := client.Store.ID != nil && jid.User == client.Store.ID.User
+		if announce || isBot {
+			sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s foi rebaixado%s.", jid.User, by), mentions(jid.User))
+		}
+		if isBot || actor == "" || actor == jid.User || isOwnerNumber(actor) || !isProtectedAdmin(groupJID, jid.User) {
+			continue
+		}
+		if !isBotAdmin(evt.JID) {
+			sendMention(evt.JID, fmt.Sprintf("*[OdinBOT]* @%s e um admin protegido, mas nao sou admin para restaurar.", jid.User), []string{jid.User})
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		restored, demoted := false, false
+		if _, err := client.UpdateGroupParticipants(ctx, evt.JID, []types.JID{jid}, whatsmeow.ParticipantChangePromote); err != nil {
+			fmt.Printf("[ERRO] Repromover %s: %v\n", jid.User, err)
+		} else {
+			restored = true
+			logAction(groupJID, BotName, jid.User, "promover", "Admin protegido", SourceAuto)
+		}
+		// Um admin protegido rebaixando outro nao perde o proprio admin
+		actorProtected := isProtectedAdmin(groupJID, actor)
+		if !actorProtected {
+			if _, err := client.UpdateGroupParticipants(ctx, evt.JID, []types.JID{*evt.Sender}, whatsmeow.ParticipantChangeDemote); err != nil {
+				fmt.Printf("[ERRO] Rebaixar %s: %v\n", actor, err)
+			} else {
+				demoted = true
+				logAction(groupJID, BotName, actor, "rebaixar", "Rebaixou admin protegido", SourceAuto)
+			}
+		}
+		cancel()
+
+		text := fmt.Sprintf("*[OdinBOT]* @%s e um admin protegido!", jid.User)
+		if restored {
+			text += " Admin restaurado."
+		} else {
+			text += " Nao consegui restaurar o admin."
+		}
+		switch {
+		case demoted:
+			text += fmt.Sprintf(" @%s foi rebaixado.", actor)
+		case !actorProtected:
+			text += fmt.Sprintf(" Nao consegui rebaixar @%s.", actor)
+		}
+		sendMention(evt.JID, text, []string{jid.User, actor})
+	}
+}
+
+// ============================================================
+// Media Filters
+// ============================================================
+
+// Chave usada para filtrar mensagens de visualizacao unica, de qualquer tipo
+const mediaViewOnce = "visualizacaounica"
+
+var mediaFilterKinds = []string{KindSticker, KindAudio, KindDocument, KindImage, KindVideo, mediaViewOnce, KindContact, KindLocation, KindPoll}
+
+var mediaFilterActions = []string{"apagar", "advertir", "remover"}
+
+// formatMediaFilters exige botData.mu travado.
+func formatMediaFilters(filters map[string]string) string {
+	var out []string
+	for _, kind := range mediaFilterKinds {
+		if action, ok := filters[kind]; ok {
+			out = append(out, kind+"="+action)
+		}
+	}
+	if len(out) == 0 {
+		return "OFF"
+	}
+	return strings.Join(out, ", ")
+}
+
+// checkMediaFilter aplica o filtro do tipo da mensagem. Admins, cargos e o dono
+// sao isentos. Retorna true se a mensagem foi barrada.
+func checkMediaFilter(chat types.JID, sender types.JID, id types.MessageID, content messageContent) bool {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.RLock()
+	kind := content.Kind
+	action, ok := cfg.MediaFilters[kind]
+	if content.ViewOnce {
+		if a, vo := cfg.MediaFilters[mediaViewOnce]; vo {
+			kind, action, ok = mediaViewOnce, a, true
+		}
+	}
+	botData.mu.RUnlock()
+	if !ok || isModerationExempt(chat, sender) {
+		return false
+	}
+
+	reason := fmt.Sprintf("Enviou %s (proibido no grupo)", kind)
+	deleteMessage(chat, sender, id)
+	switch action {
+	case "advertir":
+		warnUser(chat, sender, BotName, reason, SourceAuto)
+	case "remover":
+		banUser(chat, sender, BotName, reason, SourceAuto)
+	default:
+		logAction(gJID, BotName, sender.User, "apagar", reason, SourceAuto)
+	}
+	return true
+}
+
+// cmdMediaFilter: #antimidia <tipo> <apagar|advertir|remover|off>
+func cmdMediaFilter(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(strings.ToLower(args))
+	if len(parts) < 2 || !containsString(mediaFilterKinds, parts[0]) || (parts[1] != "off" && !containsString(mediaFilterActions, parts[1])) {
+		botData.mu.RLock()
+		current := formatMediaFilters(cfg.MediaFilters)
+		botData.mu.RUnlock()
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Filtros ativos: %s\n\nUso: #antimidia <tipo> <acao|off>\nTipos: %s\nAcoes: %s",
+			current, strings.Join(mediaFilterKinds, ", "), strings.Join(mediaFilterActions, ", ")))
+		return
+	}
+	kind, action := parts[0], parts[1]
+	botData.mu.Lock()
+	if action == "off" {
+		delete(cfg.MediaFilters, kind)
+	} else {
+		if cfg.MediaFilters == nil {
+			cfg.MediaFilters = make(map[string]string)
+		}
+		cfg.MediaFilters[kind] = action
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, sender.User, "", "config", "antimidia "+kind+" "+action, SourceCommand)
+	if action == "off" {
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Filtro de %s desativado.", kind))
+		return
+	}
+	msg := fmt.Sprintf("*[OdinBOT]* Filtro de %s ativado! Acao: %s.", kind, action)
+	if !isBotAdmin(chat) {
+		msg += "\nAtencao: preciso ser admin para apagar mensagens."
+	}
+	sendText(chat, msg)
+}
+
+// ============================================================
+// Anti-Trava
+// ============================================================
+
+var defaultTravaLimits = TravaLimits{
+	MaxLength:    15000,
+	MaxCombining: 30,
+	MaxInvisible: 30,
+	MaxMentions:  200,
+	MaxContacts:  20,
+}
+
+// Abaixo disso as porcentagens nao sao avaliadas (evita falso positivo em textos curtos)
+const travaMinChars = 100
+
+func isInvisibleRune(r rune) bool {
+	switch r {
+	case '\u115F', '\u1160', '\u3164', '\u2800', '\uFFA0':
+		return true
+	}
+	return unicode.Is(unicode.Cf, r)
+}
+
+// detectTrava retorna o motivo quando a mensagem tem cara de trava, ou "".
+func detectTrava(content messageContent, limits TravaLimits) string {
+	m := content.Message
+	if n := len(content.Context.GetMentionedJID()); n > limits.MaxMentions {
+		return fmt.Sprintf("%d mencoes", n)
+	}
+	contacts := m.GetContactsArrayMessage().GetContacts()
+	if len(contacts) > limits.MaxContacts {
+		return fmt.Sprintf("%d contatos", len(contacts))
+	}
+
+	size := utf8.RuneCountInString(content.Text) + utf8.RuneCountInString(m.GetContactMessage().GetVcard())
+	for _, ct := range contacts {
+		size += utf8.RuneCountInString(ct.GetVcard())
+	}
+	if size > limits.MaxLength {
+		return fmt.Sprintf("mensagem gigante (%d caracteres)", size)
+	}
+
+	total, combining, invisible := 0, 0, 0
+	for _, r := range content.Text {
+		total++
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			combining++
+		} else if isInvisibleRune(r) {
+			invisible++
+		}
+	}
+	if total < travaMinChars {
+		return ""
+	}
+	if combining*100 > limits.MaxCombining*total {
+		return fmt.Sprintf("excesso de acentos combinantes (%d%%)", combining*100/total)
+	}
+	if invisible*100 > limits.MaxInvisible*total {
+		return fmt.Sprintf("excesso de caracteres invisiveis (%d%%)", invisible*100/total)
+	}
+	return ""
+}
+
+// checkTrava apaga a trava, remove o autor e o coloca na lista negra global.
+// So o dono e isento: uma trava de admin trava os celulares do mesmo jeito.
+func checkTrava(chat types.JID, sender types.JID, id types.MessageID, content messageContent) bool {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	botData.mu.RLock()
+	enabled := cfg.AntiTrava
+	limits := defaultTravaLimits
+	if cfg.TravaLimits != nil {
+		limits = *cfg.TravaLimits
+	}
+	botData.mu.RUnlock()
+	if !enabled || isOwnerNumber(sender.User) {
+		return false
+	}
+	reason := detectTrava(content, limits)
+	if reason == "" {
+		return false
+	}
+
+	deleteMessage(chat, sender, id)
+	removeMember(chat, sender)
+	botData.mu.Lock()
+	botData.Blacklist[sender.User] = BlacklistEntry{
+		Number:  sender.User,
+		Reason:  "Trava: " + reason,
+		Date:    time.Now().Format("2006-01-02"),
+		AddedBy: "auto",
+	}
+	botData.mu.Unlock()
+	saveBotData()
+	logAction(gJID, BotName, sender.User, "antitrava", reason, SourceAuto)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Trava detectada e apagada (%s). O autor foi removido e colocado na lista negra.", reason))
+	return true
+}
+
+var travaLimitFields = map[string]string{
+	"texto":       "caracteres",
+	"combinantes": "% de acentos combinantes",
+	"invisiveis":  "% de caracteres invisiveis",
+	"mencoes":     "mencoes",
+	"contatos":    "contatos",
+}
+
+// cmdAntiTrava: #antitrava liga/desliga; #antitrava <campo> <valor> ajusta um limite.
+func cmdAntiTrava(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(strings.ToLower(args))
+
+	if len(parts) == 0 {
 		botData.mu.Lock()
-		botData.Blacklist[target.User] = BlacklistEntry{
-			Number:  target.User,
-			Reason:  "3 advertencias",
-			Date:    time.Now().Format("2006-01-02"),
-			AddedBy: "auto",
-		}
+		cfg.AntiTrava = !cfg.AntiTrava
+		enabled := cfg.AntiTrava
 		botData.mu.Unlock()
 		saveBotData()
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s atingiu 3 advertencias e foi banido + lista negra!", target.User))
+		status := "ativado"
+		if !enabled {
+			status = "desativado"
+		}
+		logAction(gJID, sender.User, "", "config", "antitrava "+status, SourceCommand)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-trava %s!", status))
+		return
 	}
-}
 
-func addWarningAuto(groupJID string, user types.JID, reason string) {
-	w := Warning{
-		GroupJID: groupJID,
-		UserJID:  user.User,
-		UserName: user.User,
-		Reason:   reason,
-		Date:     time.Now().Format("2006-01-02 15:04"),
-		IssuedBy: "OdinBOT",
+	if parts[0] == "limites" || parts[0] == "padrao" {
+		botData.mu.Lock()
+		if parts[0] == "padrao" {
+			cfg.TravaLimits = nil
+		}
+		l := defaultTravaLimits
+		if cfg.TravaLimits != nil {
+			l = *cfg.TravaLimits
+		}
+		botData.mu.Unlock()
+		if parts[0] == "padrao" {
+			saveBotData()
+			logAction(gJID, sender.User, "", "config", "antitrava limites padrao", SourceCommand)
+		}
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Limites do anti-trava:*\n\n- texto: %d caracteres\n- combinantes: %d%%\n- invisiveis: %d%%\n- mencoes: %d\n- contatos: %d\n\nAlterar: #antitrava <campo> <valor> | Restaurar: #antitrava padrao",
+			l.MaxLength, l.MaxCombining, l.MaxInvisible, l.MaxMentions, l.MaxContacts))
+		return
+	}
+
+	_, known := travaLimitFields[parts[0]]
+	value := 0
+	if len(parts) == 2 {
+		value, _ = strconv.Atoi(parts[1])
+	}
+	if !known || value <= 0 || ((parts[0] == "combinantes" || parts[0] == "invisiveis") && value > 100) {
+		sendText(chat, "*[OdinBOT]* Uso: #antitrava | #antitrava limites | #antitrava padrao | #antitrava <texto|combinantes|invisiveis|mencoes|contatos> <valor>")
+		return
 	}
 	botData.mu.Lock()
-	botData.Warnings[groupJID] = append(botData.Warnings[groupJID], w)
+	if cfg.TravaLimits == nil {
+		l := defaultTravaLimits
+		cfg.TravaLimits = &l
+	}
+	switch parts[0] {
+	case "texto":
+		cfg.TravaLimits.MaxLength = value
+	case "combinantes":
+		cfg.TravaLimits.MaxCombining = value
+	case "invisiveis":
+		cfg.TravaLimits.MaxInvisible = value
+	case "mencoes":
+		cfg.TravaLimits.MaxMentions = value
+	case "contatos":
+		cfg.TravaLimits.MaxContacts = value
+	}
 	botData.mu.Unlock()
 	saveBotData()
+	logAction(gJID, sender.User, "", "config", fmt.Sprintf("antitrava %s %d", parts[0], value), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Limite de %s do anti-trava: %d %s", parts[0], value, travaLimitFields[parts[0]]))
 }
 
-func cmdCheckWarnings(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para ver advertencias.")
-		return
+// ============================================================
+// Anti Mass Mention
+// ============================================================
+
+type mentionHit struct {
+	At    time.Time
+	Users []string
+}
+
+var (
+	mentionMu      sync.Mutex
+	mentionHistory = make(map[string][]mentionHit) // grupo|membro -> marcacoes recentes
+)
+
+// mentionedUsers retorna os membros distintos marcados. Marcar o proprio grupo
+// (mencao de grupo) notifica todo mundo e conta como marcar todos.
+func mentionedUsers(chat types.JID, content messageContent) []string {
+	ctx := content.Context
+	seen := make(map[string]bool)
+	var users []string
+	for _, j := range ctx.GetMentionedJID() {
+		jid, err := types.ParseJID(j)
+		if err != nil || seen[jid.User] {
+			continue
+		}
+		seen[jid.User] = true
+		users = append(users, jid.User)
+	}
+	// Mencao de subgrupo ou do grupo em um status alcanca todos os participantes
+	if len(ctx.GetGroupMentions()) > 0 || content.StatusMention {
+		if info, err := client.GetGroupInfo(context.Background(), chat); err == nil {
+			for _, p := range info.Participants {
+				if !seen[p.JID.User] {
+					seen[p.JID.User] = true
+					users = append(users, p.JID.User)
+				}
+			}
+		}
 	}
+	return users
+}
+
+// checkMassMention aplica a regra de marcacoes do grupo a membros comuns.
+// Retorna true se a mensagem foi barrada.
+func checkMassMention(chat types.JID, sender types.JID, id types.MessageID, content messageContent) bool {
 	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
 	botData.mu.RLock()
-	warns := botData.Warnings[gJID]
+	if cfg.MentionRule == nil {
+		botData.mu.RUnlock()
+		return false
+	}
+	rule := *cfg.MentionRule
 	botData.mu.RUnlock()
-	count := 0
-	details := ""
-	for _, w := range warns {
-		if w.UserJID == target.User {
-			count++
-			details += fmt.Sprintf("  %d. %s - %s\n", count, w.Reason, w.Date)
+	if len(content.Context.GetMentionedJID()) == 0 && len(content.Context.GetGroupMentions()) == 0 && !content.StatusMention {
+		return false
+	}
+	if isModerationExempt(chat, sender) {
+		return false
+	}
+
+	users := mentionedUsers(chat, content)
+	reason := ""
+	if rule.PerMessage > 0 && len(users) > rule.PerMessage {
+		reason = fmt.Sprintf("Marcou %d membros em uma mensagem", len(users))
+	}
+
+	if rule.PerWindow > 0 && rule.Window > 0 {
+		now := time.Now()
+		key := gJID + "|" + sender.User
+		mentionMu.Lock()
+		var kept []mentionHit
+		distinct := make(map[string]bool)
+		for _, h := range append(mentionHistory[key], mentionHit{now, users}) {
+			if now.Sub(h.At) > time.Duration(rule.Window)*time.Minute {
+				continue
+			}
+			kept = append(kept, h)
+			for _, u := range h.Users {
+				distinct[u] = true
+			}
+		}
+		mentionHistory[key] = kept
+		mentionMu.Unlock()
+		if reason == "" && len(distinct) > rule.PerWindow {
+			reason = fmt.Sprintf("Marcou %d membros em %d min", len(distinct), rule.Window)
 		}
 	}
-	if count == 0 {
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s nao tem advertencias.", target.User))
-	} else {
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s tem %d advertencia(s):\n%s", target.User, count, details))
+	if reason == "" {
+		return false
+	}
+
+	deleteMessage(chat, sender, id)
+	switch rule.Action {
+	case "advertir":
+		warnUser(chat, sender, BotName, reason, SourceAuto)
+	case "remover":
+		banUser(chat, sender, BotName, reason, SourceAuto)
+	default:
+		logAction(gJID, BotName, sender.User, "antimarcar", reason, SourceAuto)
+		sendMention(chat, fmt.Sprintf("*[OdinBOT]* @%s evite marcar tanta gente! Mensagem apagada.", sender.User), []string{sender.User})
 	}
+	return true
 }
 
-func cmdRemoveWarning(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para remover advertencia.")
+// cmdMentionRule: #antimarcar <por_msg> [por_janela minutos] [apagar|advertir|remover] | off
+func cmdMentionRule(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(strings.ToLower(args))
+	usage := "*[OdinBOT]* Uso: #antimarcar <por_msg> [por_janela minutos] [apagar|advertir|remover]\nEx: #antimarcar 5 15 10 advertir (ate 5 por mensagem e 15 em 10 min)\nUse 0 para nao limitar. Desativar: #antimarcar off"
+
+	if len(parts) == 0 {
+		botData.mu.RLock()
+		rule := cfg.MentionRule
+		var current string
+		if rule != nil {
+			current = fmt.Sprintf("Por mensagem: %d | Por janela: %d em %d min | Acao: %s", rule.PerMessage, rule.PerWindow, rule.Window, rule.Action)
+		}
+		botData.mu.RUnlock()
+		if current == "" {
+			current = "desativado"
+		}
+		sendText(chat, "*[OdinBOT]* Anti-marcacao: "+current+"\n\n"+usage)
 		return
 	}
-	gJID := chat.String()
-	botData.mu.Lock()
-	warns := botData.Warnings[gJID]
-	for i := len(warns) - 1; i >= 0; i-- {
-		if warns[i].UserJID == target.User {
-			botData.Warnings[gJID] = append(warns[:i], warns[i+1:]...)
-			break
+
+	if parts[0] == "off" {
+		botData.mu.Lock()
+		cfg.MentionRule = nil
+		botData.mu.Unlock()
+		saveBotData()
+		logAction(gJID, sender.User, "", "config", "antimarcar off", SourceCommand)
+		sendText(chat, "*[OdinBOT]* Anti-marcacao desativado.")
+		return
+	}
+
+	rule := MentionRule{Action: "apagar"}
+	if last := parts[len(parts)-1]; containsString(mediaFilterActions, last) {
+		rule.Action = last
+		parts = parts[:len(parts)-1]
+	}
+	var nums []int
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			sendText(chat, usage)
+			return
 		}
+		nums = append(nums, n)
+	}
+	if len(nums) != 1 && len(nums) != 3 {
+		sendText(chat, usage)
+		return
 	}
+	rule.PerMessage = nums[0]
+	if len(nums) == 3 {
+		rule.PerWindow, rule.Window = nums[1], nums[2]
+	}
+	if rule.PerMessage == 0 && (rule.PerWindow == 0 || rule.Window == 0) {
+		sendText(chat, usage)
+		return
+	}
+	botData.mu.Lock()
+	cfg.MentionRule = &rule
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Uma advertencia de @%s foi removida.", target.User))
+	logAction(gJID, sender.User, "", "config", fmt.Sprintf("antimarcar %d %d/%dmin %s", rule.PerMessage, rule.PerWindow, rule.Window, rule.Action), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-marcacao ativado!\nPor mensagem: %d | Por janela: %d em %d min | Acao: %s", rule.PerMessage, rule.PerWindow, rule.Window, rule.Action))
 }
 
-func cmdClearWarnings(chat types.JID) {
-	gJID := chat.String()
+// ============================================================
+// Anti-Call
+// ============================================================
+
+var defaultAntiCall = AntiCallConfig{Enabled: true, BlockAfter: 3, Notify: true}
+
+func antiCallSettings() AntiCallConfig {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	if botData.AntiCall == nil {
+		return defaultAntiCall
+	}
+	return *botData.AntiCall
+}
+
+// handleCall rejeita ligacoes para o numero do bot. Na primeira o autor recebe um
+// aviso no privado; a partir de BlockAfter ligacoes ele e bloqueado.
+func handleCall(call types.BasicCallMeta) {
+	cfg := antiCallSettings()
+	caller := call.CallCreator
+	if caller.User == "" {
+		caller = call.From
+	}
+	if !cfg.Enabled || isOwnerNumber(caller.User) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := client.RejectCall(ctx, call.From, call.CallID); err != nil {
+		fmt.Printf("[ERRO] Rejeitar ligacao de %s: %v\n", caller.User, err)
+	}
+
 	botData.mu.Lock()
-	delete(botData.Warnings, gJID)
+	botData.CallCounts[caller.User]++
+	count := botData.CallCounts[caller.User]
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, "*[OdinBOT]* Todas as advertencias do grupo foram limpas.")
+	logAction("", BotName, caller.User, "antiligacao", fmt.Sprintf("Ligacao rejeitada (%d)", count), SourceAuto)
+
+	dm := caller.ToNonAD()
+	blocked := cfg.BlockAfter > 0 && count >= cfg.BlockAfter
+	switch {
+	case blocked:
+		sendText(dm, fmt.Sprintf("*[OdinBOT]* Voce ligou %d vezes para o bot e foi bloqueado.", count))
+		if _, err := client.UpdateBlocklist(ctx, dm, events.BlocklistChangeActionBlock); err != nil {
+			fmt.Printf("[ERRO] Bloquear %s: %v\n", caller.User, err)
+			blocked = false
+		} else {
+			logAction("", BotName, caller.User, "bloquear", fmt.Sprintf("%d ligacoes", count), SourceAuto)
+		}
+	case count == 1:
+		msg := "*[OdinBOT]* Este numero e um bot e nao atende ligacoes. A ligacao foi rejeitada automaticamente."
+		if cfg.BlockAfter > 0 {
+			msg += fmt.Sprintf("\nApos %d ligacoes o numero sera bloqueado.", cfg.BlockAfter)
+		}
+		sendText(dm, msg)
+	}
+
+	if cfg.Notify {
+		notice := fmt.Sprintf("*[OdinBOT]* Ligacao rejeitada de wa.me/%s (%d no total).", caller.User, count)
+		if blocked {
+			notice += "\nNumero bloqueado."
+		}
+		sendText(types.NewJID(OwnerNumber, types.DefaultUserServer), notice)
+	}
 }
 
-func cmdListWarnings(chat types.JID) {
-	gJID := chat.String()
-	botData.mu.RLock()
-	warns := botData.Warnings[gJID]
-	botData.mu.RUnlock()
-	if len(warns) == 0 {
-		sendText(chat, "*[OdinBOT]* Nenhuma advertencia neste grupo.")
+// cmdAntiCall: #antiligar [on|off | bloquear N | avisar on|off | zerar numero]
+func cmdAntiCall(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(strings.ToLower(args))
+	cfg := antiCallSettings()
+	usage := "Uso: #antiligar on|off | bloquear N (0 = nunca) | avisar on|off | zerar numero"
+
+	if len(parts) == 0 {
+		status := "OFF"
+		if cfg.Enabled {
+			status = "ON"
+		}
+		notify := "OFF"
+		if cfg.Notify {
+			notify = "ON"
+		}
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Anti-ligacao:*\n\n- Rejeitar: %s\n- Bloquear apos: %d ligacoes\n- Avisar dono: %s\n\n%s", status, cfg.BlockAfter, notify, usage))
 		return
 	}
-	msg := "*[OdinBOT] Advertidos:*\n\n"
-	userWarns := make(map[string]int)
-	for _, w := range warns {
-		userWarns[w.UserJID]++
+
+	switch {
+	case parts[0] == "on" || parts[0] == "off":
+		cfg.Enabled = parts[0] == "on"
+	case parts[0] == "bloquear" && len(parts) == 2:
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 0 {
+			sendText(chat, "*[OdinBOT]* "+usage)
+			return
+		}
+		cfg.BlockAfter = n
+	case parts[0] == "avisar" && len(parts) == 2 && (parts[1] == "on" || parts[1] == "off"):
+		cfg.Notify = parts[1] == "on"
+	case parts[0] == "zerar" && len(parts) == 2:
+		number := strings.TrimPrefix(parts[1], "@")
+		botData.mu.Lock()
+		delete(botData.CallCounts, number)
+		botData.mu.Unlock()
+		saveBotData()
+		logAction("", sender.User, number, "config", "antiligar zerar", SourceCommand)
+		sendText(chat, fmt.Sprintf("*[OdinBOT]* Contador de ligacoes de %s zerado.", number))
+		return
+	default:
+		sendText(chat, "*[OdinBOT]* "+usage)
+		return
 	}
-	for user, count := range userWarns {
-		msg += fmt.Sprintf("- @%s: %d advertencia(s)\n", user, count)
+
+	botData.mu.Lock()
+	botData.AntiCall = &cfg
+	botData.mu.Unlock()
+	saveBotData()
+	logAction("", sender.User, "", "config", "antiligar "+strings.Join(parts, " "), SourceCommand)
+	sendText(chat, "*[OdinBOT]* Anti-ligacao atualizado!")
+}
+
+// ============================================================
+// Cross-Group Spam
+// ============================================================
+
+// Spam em massa chega em varios grupos ao mesmo tempo com o mesmo conteudo. Cada
+// mensagem vira um hash (texto normalizado ou SHA256 da midia) e as ocorrencias
+// recentes ficam em memoria, de todos os grupos juntos.
+type CrossSpamConfig struct {
+	Enabled    bool `json:"enabled"`
+	Groups     int  `json:"groups"`      // K: grupos diferentes com o mesmo conteudo
+	Minutes    int  `json:"minutes"`     // T: janela de tempo
+	NewMembers int  `json:"new_members"` // membros novos diferentes para o caso "varios novatos"
+	NewHours   int  `json:"new_hours"`   // entrou ha menos de X horas = membro novo
+}
+
+var defaultCrossSpam = CrossSpamConfig{Enabled: false, Groups: 3, Minutes: 10, NewMembers: 3, NewHours: 24}
+
+const crossSpamMinText = 20 // textos curtos ("bom dia") se repetem naturalmente
+
+type spamSighting struct {
+	Chat      types.JID
+	Sender    types.JID
+	ID        types.MessageID
+	Time      time.Time
+	NewMember bool
+}
+
+var (
+	crossSpamMu      sync.Mutex
+	crossSpamSeen    = make(map[string][]spamSighting) // hash -> ocorrencias dentro da janela
+	crossSpamFlagged = make(map[string]time.Time)      // hash ja confirmado como spam -> quando
+	memberJoins      = make(map[string]time.Time)      // grupo|usuario -> entrada no grupo
+	crossSpamPruned  time.Time
+)
+
+func crossSpamSettings() CrossSpamConfig {
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	if botData.CrossSpam == nil {
+		return defaultCrossSpam
 	}
-	sendText(chat, msg)
+	return *botData.CrossSpam
 }
 
-func cmdMute(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para mutar.")
-		return
+// recordMemberJoin guarda quando o membro entrou, para o caso de spam vindo de novatos.
+func recordMemberJoin(chat types.JID, user types.JID) {
+	crossSpamMu.Lock()
+	memberJoins[chat.String()+"|"+user.User] = time.Now()
+	crossSpamMu.Unlock()
+}
+
+// spamContentHash retorna "" para conteudo que nao deve ser comparado.
+func spamContentHash(content messageContent) string {
+	type media interface {
+		GetFileSHA256() []byte
+	}
+	var md media
+	switch content.Kind {
+	case KindImage:
+		md = content.Message.GetImageMessage()
+	case KindVideo:
+		md = content.Message.GetVideoMessage()
+	case KindDocument:
+		md = content.Message.GetDocumentMessage()
+	}
+	if md != nil && len(md.GetFileSHA256()) > 0 {
+		return content.Kind + ":" + hex.EncodeToString(md.GetFileSHA256())
+	}
+
+	// Texto: minusculas, so letras e numeros, espacos colapsados
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(content.Text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
 	}
-	gJID := chat.String()
-	botData.mu.Lock()
-	if botData.MutedUsers[gJID] == nil {
-		botData.MutedUsers[gJID] = make(map[string]bool)
+	norm := b.String()
+	if len([]rune(norm)) < crossSpamMinText {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(norm))
+	return "texto:" + hex.EncodeToString(sum[:16])
+}
+
+// checkCrossGroupSpam registra a mensagem e, quando o mesmo conteudo aparece em K grupos
+// dentro de T minutos (do mesmo autor ou de varios membros novos), apaga todas as copias,
+// remove os autores onde o bot e admin e os coloca na lista negra global.
+func checkCrossGroupSpam(chat types.JID, sender types.JID, id types.MessageID, content messageContent) bool {
+	cfg := crossSpamSettings()
+	if !cfg.Enabled || isOwnerNumber(sender.User) {
+		return false
+	}
+	hash := spamContentHash(content)
+	if hash == "" || isModerationExempt(chat, sender) {
+		return false
+	}
+
+	now := time.Now()
+	window := time.Duration(cfg.Minutes) * time.Minute
+	crossSpamMu.Lock()
+	// Limpeza das ocorrencias antigas no maximo uma vez por minuto
+	if now.Sub(crossSpamPruned) > time.Minute {
+		crossSpamPruned = now
+		for h, at := range crossSpamFlagged {
+			if now.Sub(at) > window {
+				delete(crossSpamFlagged, h)
+			}
+		}
+		for h, list := range crossSpamSeen {
+			if now.Sub(list[len(list)-1].Time) > window {
+				delete(crossSpamSeen, h)
+			}
+		}
+		for k, at := range memberJoins {
+			if now.Sub(at) > time.Duration(cfg.NewHours)*time.Hour {
+				delete(memberJoins, k)
+			}
+		}
+	}
+	joined, ok := memberJoins[chat.String()+"|"+sender.User]
+	isNew := ok && now.Sub(joined) < time.Duration(cfg.NewHours)*time.Hour
+	s := spamSighting{Chat: chat, Sender: sender, ID: id, Time: now, NewMember: isNew}
+	seen := crossSpamSeen[hash][:0]
+	for _, old := range crossSpamSeen[hash] {
+		if now.Sub(old.Time) <= window {
+			seen = append(seen, old)
+		}
+	}
+	seen = append(seen, s)
+	crossSpamSeen[hash] = seen
+
+	var targets []spamSighting
+	reason := ""
+	if _, flagged := crossSpamFlagged[hash]; flagged {
+		// Conteudo ja confirmado: a copia cai direto, mas so membro novo vai para a
+		// lista negra (um membro antigo pode estar so citando o spam como alerta)
+		if !s.NewMember {
+			crossSpamMu.Unlock()
+			if isBotAdmin(chat) {
+				deleteMessage(chat, sender, id)
+			}
+			logAction(chat.String(), BotName, sender.User, "spamcruzado", "copia de conteudo ja identificado como spam apagada", SourceAuto)
+			return true
+		}
+		targets = []spamSighting{s}
+		reason = "conteudo ja identificado como spam"
+	} else {
+		senderChats := map[string]bool{}
+		newChats := map[string]bool{}
+		newSenders := map[string]bool{}
+		for _, o := range seen {
+			if o.Sender.User == sender.User {
+				senderChats[o.Chat.String()] = true
+			}
+			if o.NewMember {
+				newChats[o.Chat.String()] = true
+				newSenders[o.Sender.User] = true
+			}
+		}
+		switch {
+		case len(senderChats) >= cfg.Groups:
+			for _, o := range seen {
+				if o.Sender.User == sender.User {
+					targets = append(targets, o)
+				}
+			}
+			reason = fmt.Sprintf("mesmo conteudo em %d grupos em %d min", len(senderChats), cfg.Minutes)
+		case s.NewMember && len(newChats) >= cfg.Groups && len(newSenders) >= cfg.NewMembers:
+			for _, o := range seen {
+				if o.NewMember {
+					targets = append(targets, o)
+				}
+			}
+			reason = fmt.Sprintf("mesmo conteudo de %d membros novos em %d grupos em %d min", len(newSenders), len(newChats), cfg.Minutes)
+		}
+		if len(targets) > 0 {
+			crossSpamFlagged[hash] = now
+		}
+	}
+	if len(targets) > 0 {
+		delete(crossSpamSeen, hash)
+	}
+	crossSpamMu.Unlock()
+
+	if len(targets) == 0 {
+		return false
 	}
-	botData.MutedUsers[gJID][target.User] = true
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi mutado.", target.User))
+	punishCrossGroupSpam(hash, content, targets, reason)
+	return true
 }
 
-func cmdUnmute(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para desmutar.")
-		return
+// punishCrossGroupSpam apaga as copias, remove os autores e registra a evidencia na lista negra.
+func punishCrossGroupSpam(hash string, content messageContent, targets []spamSighting, reason string) {
+	preview := content.Text
+	if r := []rune(preview); len(r) > 100 {
+		preview = string(r[:100]) + "..."
 	}
-	gJID := chat.String()
-	botData.mu.Lock()
-	if botData.MutedUsers[gJID] != nil {
-		delete(botData.MutedUsers[gJID], target.User)
+	if preview == "" {
+		preview = "(" + content.Kind + ")"
 	}
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi desmutado.", target.User))
-}
 
-func cmdPromote(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para promover.")
-		return
+	groups := map[string]bool{}
+	senders := map[string][]string{} // autor -> grupos
+	admin := map[string]bool{}       // grupo -> bot e admin
+	for _, t := range targets {
+		g := t.Chat.String()
+		if _, ok := admin[g]; !ok {
+			admin[g] = isBotAdmin(t.Chat)
+		}
+		if admin[g] {
+			deleteMessage(t.Chat, t.Sender, t.ID)
+		}
+		groups[g] = true
+		if !containsString(senders[t.Sender.User], g) {
+			senders[t.Sender.User] = append(senders[t.Sender.User], g)
+		}
 	}
-	_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{*target}, whatsmeow.ParticipantChangePromote)
-	if err != nil {
-		sendText(chat, "*[OdinBOT]* Erro ao promover.")
-		return
+
+	for number, chats := range senders {
+		user := types.NewJID(number, types.DefaultUserServer)
+		names := make([]string, 0, len(chats))
+		for _, g := range chats {
+			jid, err := types.ParseJID(g)
+			if err != nil {
+				continue
+			}
+			names = append(names, getGroupName(jid))
+			if admin[g] && !isModerationExempt(jid, user) {
+				removeMember(jid, user)
+			}
+			logAction(g, BotName, number, "spamcruzado", reason, SourceAuto)
+		}
+		evidence := fmt.Sprintf("%s | grupos: %s | conteudo: %s | hash: %s", reason, strings.Join(names, ", "), preview, hash)
+		botData.mu.Lock()
+		if _, exists := botData.Blacklist[number]; !exists {
+			botData.Blacklist[number] = BlacklistEntry{
+				Number:   number,
+				Reason:   "Spam em varios grupos",
+				Date:     time.Now().Format("2006-01-02"),
+				AddedBy:  "auto",
+				Evidence: evidence,
+			}
+		}
+		botData.mu.Unlock()
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi promovido a admin!", target.User))
+	saveBotData()
+
+	numbers := make([]string, 0, len(senders))
+	for number := range senders {
+		numbers = append(numbers, number)
+	}
+	sendText(types.NewJID(OwnerNumber, types.DefaultUserServer), fmt.Sprintf(
+		"*[OdinBOT] Spam entre grupos*\n\nMotivo: %s\nAutores: %s\nGrupos: %d\nConteudo: %s\n\nMensagens apagadas e autores na lista negra global.",
+		reason, strings.Join(numbers, ", "), len(groups), preview))
 }
 
-func cmdDemote(chat types.JID, msg *events.Message) {
-	target := getMentionedJID(msg)
-	if target == nil {
-		sendText(chat, "*[OdinBOT]* Mencione alguem para rebaixar.")
+// cmdCrossSpam: #spamcruzado [on|off | grupos K | minutos T | novatos N | horas H]
+func cmdCrossSpam(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(strings.ToLower(args))
+	cfg := crossSpamSettings()
+	usage := "Uso: #spamcruzado on|off | grupos K | minutos T | novatos N | horas H"
+
+	if len(parts) == 0 {
+		status := "OFF"
+		if cfg.Enabled {
+			status = "ON"
+		}
+		sendText(chat, fmt.Sprintf("*[OdinBOT] Spam entre grupos:*\n\n- Status: %s\n- Mesmo conteudo em %d grupos dentro de %d min\n- Ou de %d membros novos (entraram ha menos de %dh)\n\n%s",
+			status, cfg.Groups, cfg.Minutes, cfg.NewMembers, cfg.NewHours, usage))
 		return
 	}
-	_, err := client.UpdateGroupParticipants(context.Background(), chat, []types.JID{*target}, whatsmeow.ParticipantChangeDemote)
-	if err != nil {
-		sendText(chat, "*[OdinBOT]* Erro ao rebaixar.")
-		return
+
+	if parts[0] == "on" || parts[0] == "off" {
+		cfg.Enabled = parts[0] == "on"
+	} else {
+		fields := map[string]*int{"grupos": &cfg.Groups, "minutos": &cfg.Minutes, "novatos": &cfg.NewMembers, "horas": &cfg.NewHours}
+		field, ok := fields[parts[0]]
+		if !ok || len(parts) != 2 {
+			sendText(chat, "*[OdinBOT]* "+usage)
+			return
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 1 || (parts[0] == "grupos" && n < 2) {
+			sendText(chat, "*[OdinBOT]* Valor invalido (grupos minimo 2, demais minimo 1).")
+			return
+		}
+		*field = n
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* @%s foi rebaixado.", target.User))
-}
 
-func cmdToggleWelcome(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
 	botData.mu.Lock()
-	cfg.Welcome = !cfg.Welcome
+	botData.CrossSpam = &cfg
 	botData.mu.Unlock()
 	saveBotData()
-	status := "ativado"
-	if !cfg.Welcome {
-		status = "desativado"
-	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Bem-vindo %s!", status))
+	logAction("", sender.User, "", "config", "spamcruzado "+strings.Join(parts, " "), SourceCommand)
+	sendText(chat, "*[OdinBOT]* Spam entre grupos atualizado!")
 }
 
-func cmdToggleAntilink(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.Antilink = !cfg.Antilink
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.Antilink {
-		status = "desativado"
-	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-link %s!", status))
+// ============================================================
+// Message Store
+// ============================================================
+
+// Ultimas mensagens de cada chat, para respostas (#report, #deletar), anti-delete
+// e consultas de contexto. Visualizacao unica nunca e guardada. Opcionalmente, o
+// que sai do cache dos grupos vai para o SQLite (nunca privado). Grupos com
+// #antidelete ficam fora: mensagens apagadas nunca sao gravadas em disco.
+const (
+	messageStoreSize   = 300 // mensagens por chat em memoria
+	messageSpillMaxAge = 24 * time.Hour
+)
+
+type StoredMessage struct {
+	ID         types.MessageID
+	Chat       types.JID
+	Sender     types.JID
+	Time       time.Time
+	Text       string
+	Kind       string
+	ViewOnce   bool
+	MediaKey   []byte
+	DirectPath string
+	Message    *waE2E.Message // conteudo sem envelopes (para baixar ou reenviar a midia)
 }
 
-func cmdToggleAntifake(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.Antifake = !cfg.Antifake
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.Antifake {
-		status = "desativado"
-	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-fake %s!", status))
+type chatMessages struct {
+	order []types.MessageID
+	byID  map[types.MessageID]*StoredMessage
 }
 
-func cmdToggleAntiPalavrao(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.AntiPalavrao = !cfg.AntiPalavrao
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.AntiPalavrao {
-		status = "desativado"
-	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-palavrao %s!", status))
+var (
+	messageStoreMu sync.Mutex
+	messageStore   = make(map[string]*chatMessages)
+	spillDB        *sql.DB
+)
+
+func newStoredMessage(id types.MessageID, chat types.JID, sender types.JID, at time.Time, content messageContent) *StoredMessage {
+	m := &StoredMessage{
+		ID:       id,
+		Chat:     chat,
+		Sender:   sender,
+		Time:     at,
+		Text:     content.Text,
+		Kind:     content.Kind,
+		ViewOnce: content.ViewOnce,
+		Message:  content.Message,
+	}
+	type media interface {
+		GetMediaKey() []byte
+		GetDirectPath() string
+	}
+	var md media
+	switch content.Kind {
+	case KindImage:
+		md = content.Message.GetImageMessage()
+	case KindVideo:
+		md = content.Message.GetVideoMessage()
+	case KindAudio:
+		md = content.Message.GetAudioMessage()
+	case KindDocument:
+		md = content.Message.GetDocumentMessage()
+	case KindSticker:
+		md = content.Message.GetStickerMessage()
+	}
+	if md != nil {
+		m.MediaKey, m.DirectPath = md.GetMediaKey(), md.GetDirectPath()
+	}
+	return m
 }
 
-func cmdToggleAutoSticker(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.AutoSticker = !cfg.AutoSticker
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.AutoSticker {
-		status = "desativado"
+func storeMessage(msg *events.Message, content messageContent) {
+	if content.ViewOnce {
+		return
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Auto-sticker %s!", status))
-}
+	m := newStoredMessage(msg.Info.ID, msg.Info.Chat, msg.Info.Sender, msg.Info.Timestamp, content)
+	key := msg.Info.Chat.String()
+
+	var evicted []*StoredMessage
+	messageStoreMu.Lock()
+	chat := messageStore[key]
+	if chat == nil {
+		chat = &chatMessages{byID: make(map[types.MessageID]*StoredMessage)}
+		messageStore[key] = chat
+	}
+	if _, dup := chat.byID[m.ID]; !dup {
+		chat.order = append(chat.order, m.ID)
+	}
+	chat.byID[m.ID] = m
+	for len(chat.order) > messageStoreSize {
+		old := chat.order[0]
+		chat.order = chat.order[1:]
+		if e, ok := chat.byID[old]; ok {
+			evicted = append(evicted, e)
+			delete(chat.byID, old)
+		}
+	}
+	db := spillDB
+	messageStoreMu.Unlock()
 
-func cmdToggleAutoDL(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.AutoDL = !cfg.AutoDL
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.AutoDL {
-		status = "desativado"
+	if db != nil && len(evicted) > 0 && msg.Info.Chat.Server == types.GroupServer {
+		if mode, _ := antiDeleteSettings(msg.Info.Chat); mode != "" {
+			return
+		}
+		for _, e := range evicted {
+			spillMessage(db, e)
+		}
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Auto-download %s!", status))
 }
 
-func cmdToggleOnlyAdmin(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	botData.mu.Lock()
-	cfg.OnlyAdm = !cfg.OnlyAdm
-	botData.mu.Unlock()
-	saveBotData()
-	status := "ativado"
-	if !cfg.OnlyAdm {
-		status = "desativado"
+// peekMessage busca apenas no cache em memoria.
+func peekMessage(chat types.JID, id types.MessageID) (StoredMessage, bool) {
+	messageStoreMu.Lock()
+	defer messageStoreMu.Unlock()
+	if c := messageStore[chat.String()]; c != nil {
+		if m, ok := c.byID[id]; ok {
+			return *m, true
+		}
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Modo so-admin %s!", status))
+	return StoredMessage{}, false
 }
 
-func cmdCloseGroup(chat types.JID) {
-	if !isBotAdmin(chat) {
-		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
-		return
+// lookupMessage busca no cache em memoria e, se ativado, no SQLite.
+func lookupMessage(chat types.JID, id types.MessageID) (StoredMessage, bool) {
+	if m, ok := peekMessage(chat, id); ok {
+		return m, true
+	}
+	messageStoreMu.Lock()
+	db := spillDB
+	messageStoreMu.Unlock()
+	if db == nil {
+		return StoredMessage{}, false
+	}
+	var sender string
+	var ts int64
+	var raw []byte
+	err := db.QueryRow(`SELECT sender, ts, message FROM recent_messages WHERE chat = ? AND id = ?`, chat.String(), id).Scan(&sender, &ts, &raw)
+	if err != nil {
+		return StoredMessage{}, false
 	}
-	_ = client.SetGroupAnnounce(context.Background(), chat, true)
-	sendText(chat, "*[OdinBOT]* Grupo fechado! Somente admins podem enviar mensagens.")
+	senderJID, err := types.ParseJID(sender)
+	if err != nil {
+		return StoredMessage{}, false
+	}
+	content := &waE2E.Message{}
+	if err := proto.Unmarshal(raw, content); err != nil {
+		return StoredMessage{}, false
+	}
+	return *newStoredMessage(id, chat, senderJID, time.Unix(ts, 0), extractMessage(content)), true
 }
 
-func cmdOpenGroup(chat types.JID) {
-	if !isBotAdmin(chat) {
-		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
-		return
+// getQuotedMessage retorna a mensagem respondida. Se ela ja saiu do cache, usa a
+// copia que o WhatsApp envia junto com a resposta.
+func getQuotedMessage(msg *events.Message) (StoredMessage, bool) {
+	ctx := getContextInfo(msg)
+	id := types.MessageID(ctx.GetStanzaID())
+	if id == "" {
+		return StoredMessage{}, false
 	}
-	_ = client.SetGroupAnnounce(context.Background(), chat, false)
-	sendText(chat, "*[OdinBOT]* Grupo aberto! Todos podem enviar mensagens.")
+	if m, ok := lookupMessage(msg.Info.Chat, id); ok {
+		return m, true
+	}
+	sender, err := types.ParseJID(ctx.GetParticipant())
+	if err != nil || sender.User == "" {
+		return StoredMessage{}, false
+	}
+	return *newStoredMessage(id, msg.Info.Chat, sender, time.Time{}, extractMessage(ctx.GetQuotedMessage())), true
 }
 
-func cmdSetGroupName(chat types.JID, name string) {
-	if name == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #nomegp Novo Nome")
-		return
+func openMessageSpill() error {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(dataDir, "messages.db")+"?_journal_mode=WAL")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS recent_messages (
+		chat    TEXT NOT NULL,
+		id      TEXT NOT NULL,
+		sender  TEXT NOT NULL,
+		ts      INTEGER NOT NULL,
+		message BLOB,
+		PRIMARY KEY (chat, id)
+	)`)
+	if err != nil {
+		db.Close()
+		return err
 	}
-	_ = client.SetGroupName(context.Background(), chat, name)
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Nome do grupo alterado para: %s", name))
+	messageStoreMu.Lock()
+	spillDB = db
+	messageStoreMu.Unlock()
+	go pruneMessageSpill(db)
+	return nil
 }
 
-func cmdSetGroupDesc(chat types.JID, desc string) {
-	if desc == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #descgp Nova descricao")
-		return
+func closeMessageSpill() {
+	messageStoreMu.Lock()
+	db := spillDB
+	spillDB = nil
+	messageStoreMu.Unlock()
+	if db != nil {
+		db.Close()
 	}
-	_ = client.SetGroupTopic(context.Background(), chat, "", "", desc)
-	sendText(chat, "*[OdinBOT]* Descricao do grupo atualizada!")
 }
 
-func cmdGetGroupLink(chat types.JID) {
-	link, err := client.GetGroupInviteLink(context.Background(), chat, false)
+func spillMessage(db *sql.DB, m *StoredMessage) {
+	if m.ViewOnce {
+		return
+	}
+	raw, err := proto.Marshal(m.Message)
 	if err != nil {
-		sendText(chat, "*[OdinBOT]* Erro ao obter link. Preciso ser admin.")
 		return
 	}
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Link do grupo:\nhttps://chat.whatsapp.com/%s", link))
+	_, err = db.Exec(`INSERT OR REPLACE INTO recent_messages (chat, id, sender, ts, message) VALUES (?, ?, ?, ?, ?)`,
+		m.Chat.String(), m.ID, m.Sender.String(), m.Time.Unix(), raw)
+	if err != nil {
+		fmt.Printf("[ERRO] Gravar mensagem no cache: %v\n", err)
+	}
 }
 
-func cmdTagAll(chat types.JID, text string) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
+// forgetSpilledChat apaga do SQLite o que o grupo ja tinha gravado antes do #antidelete.
+func forgetSpilledChat(chat types.JID) {
+	messageStoreMu.Lock()
+	db := spillDB
+	messageStoreMu.Unlock()
+	if db == nil {
 		return
 	}
-	if text == "" {
-		text = "Atencao membros!"
+	if _, err := db.Exec(`DELETE FROM recent_messages WHERE chat = ?`, chat.String()); err != nil {
+		fmt.Printf("[ERRO] Limpar cache do grupo: %v\n", err)
 	}
-	msg := fmt.Sprintf("*[OdinBOT] %s*\n\n", text)
-	var mentions []string
-	for _, p := range info.Participants {
-		msg += fmt.Sprintf("@%s\n", p.JID.User)
-		mentions = append(mentions, p.JID.User)
+}
+
+// pruneMessageSpill apaga do SQLite as mensagens antigas ate o banco ser fechado.
+func pruneMessageSpill(db *sql.DB) {
+	for {
+		_, err := db.Exec(`DELETE FROM recent_messages WHERE ts < ?`, time.Now().Add(-messageSpillMaxAge).Unix())
+		if err != nil {
+			return
+		}
+		time.Sleep(1 * time.Hour)
 	}
-	sendMention(chat, msg, mentions)
 }
 
-func cmdHideTag(chat types.JID, text string) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
-		return
+// cmdMessageCache: #cachemsg [sqlite on|off]
+func cmdMessageCache(chat types.JID, sender types.JID, args string) {
+	parts := strings.Fields(strings.ToLower(args))
+	if len(parts) == 2 && parts[0] == "sqlite" && (parts[1] == "on" || parts[1] == "off") {
+		enable := parts[1] == "on"
+		if enable {
+			closeMessageSpill()
+			if err := openMessageSpill(); err != nil {
+				sendText(chat, "*[OdinBOT]* Erro ao abrir o SQLite: "+err.Error())
+				return
+			}
+		} else {
+			closeMessageSpill()
+		}
+		botData.mu.Lock()
+		botData.MessageSpill = enable
+		botData.mu.Unlock()
+		saveBotData()
+		logAction("", sender.User, "", "config", "cachemsg sqlite "+parts[1], SourceCommand)
 	}
-	if text == "" {
-		text = "."
+
+	messageStoreMu.Lock()
+	chats, total := len(messageStore), 0
+	for _, c := range messageStore {
+		total += len(c.order)
 	}
-	var mentions []string
-	for _, p := range info.Participants {
-		mentions = append(mentions, p.JID.User)
+	spill := spillDB != nil
+	messageStoreMu.Unlock()
+	status := "OFF"
+	if spill {
+		status = "ON"
 	}
-	sendMention(chat, text, mentions)
+	sendText(chat, fmt.Sprintf("*[OdinBOT] Cache de mensagens:*\n\n- Chats: %d\n- Mensagens em memoria: %d (max %d por chat)\n- SQLite: %s (guarda por %dh as mensagens de grupo que saem da memoria, exceto de grupos com #antidelete)\n\nUse #cachemsg sqlite on|off",
+		chats, total, messageStoreSize, status, int(messageSpillMaxAge.Hours())))
 }
 
-func cmdBanGhost(chat types.JID) {
-	if !isBotAdmin(chat) {
-		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
+// cmdDeleteMessage apaga a mensagem respondida com #deletar.
+func cmdDeleteMessage(chat types.JID, msg *events.Message, sender types.JID) {
+	quoted, ok := getQuotedMessage(msg)
+	if !ok {
+		sendText(chat, "*[OdinBOT]* Responda a mensagem que deseja apagar com #deletar.")
 		return
 	}
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
+	if !isBotAdmin(chat) && (client.Store.ID == nil || quoted.Sender.User != client.Store.ID.User) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin para apagar mensagens.")
 		return
 	}
-	var ghosts []types.JID
-	for _, p := range info.Participants {
-		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
-			_, err := client.GetProfilePictureInfo(context.Background(), p.JID, &whatsmeow.GetProfilePictureParams{})
-			if err != nil {
-				ghosts = append(ghosts, p.JID)
-			}
-		}
-	}
-	if len(ghosts) > 0 {
-		_, _ = client.UpdateGroupParticipants(context.Background(), chat, ghosts, whatsmeow.ParticipantChangeRemove)
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* %d ghosts removidos!", len(ghosts)))
-	} else {
-		sendText(chat, "*[OdinBOT]* Nenhum ghost encontrado.")
+	deleteMessage(chat, quoted.Sender, quoted.ID)
+	logAction(chat.String(), sender.User, quoted.Sender.User, "deletar", quoted.Text, SourceCommand)
+}
+
+// ============================================================
+// Anti-Delete
+// ============================================================
+
+const (
+	defaultAntiDeleteMinutes = 10
+	maxAntiDeleteMinutes     = 60
+)
+
+func antiDeleteSettings(chat types.JID) (string, time.Duration) {
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	defer botData.mu.RUnlock()
+	minutes := cfg.AntiDeleteMinutes
+	if minutes <= 0 {
+		minutes = defaultAntiDeleteMinutes
 	}
+	return cfg.AntiDelete, time.Duration(minutes) * time.Minute
 }
 
-func cmdBanFakes(chat types.JID) {
-	if !isBotAdmin(chat) {
-		sendText(chat, "*[OdinBOT]* Preciso ser admin.")
-		return
+// getRevokedID retorna o ID da mensagem apagada quando a mensagem e um "apagar para todos".
+func getRevokedID(m *waE2E.Message) types.MessageID {
+	m, _ = unwrapMessage(m)
+	p := m.GetProtocolMessage()
+	if p == nil || p.GetType() != waE2E.ProtocolMessage_REVOKE {
+		return ""
 	}
+	return p.GetKey().GetID()
+}
+
+// groupAdminJIDs lista os admins do grupo, sem o bot.
+func groupAdminJIDs(chat types.JID) []types.JID {
 	info, err := client.GetGroupInfo(context.Background(), chat)
 	if err != nil {
-		return
+		return nil
 	}
-	var fakes []types.JID
+	var admins []types.JID
 	for _, p := range info.Participants {
-		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
-			if !strings.HasPrefix(p.JID.User, "55") {
-				fakes = append(fakes, p.JID)
-			}
+		if (p.IsAdmin || p.IsSuperAdmin) && (client.Store.ID == nil || p.JID.User != client.Store.ID.User) {
+			admins = append(admins, p.JID)
 		}
 	}
-	if len(fakes) > 0 {
-		_, _ = client.UpdateGroupParticipants(context.Background(), chat, fakes, whatsmeow.ParticipantChangeRemove)
-		sendText(chat, fmt.Sprintf("*[OdinBOT]* %d fakes (numeros estrangeiros) removidos!", len(fakes)))
-	} else {
-		sendText(chat, "*[OdinBOT]* Nenhum fake encontrado.")
-	}
+	return admins
 }
 
-func cmdSorteio(chat types.JID) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
+// handleRevoke mostra o que foi apagado. So vale quando o proprio autor apagou:
+// mensagens removidas por admins (moderacao) nao voltam. Usa apenas o cache em
+// memoria, nunca o SQLite.
+func handleRevoke(chat types.JID, revoker types.JID, id types.MessageID) {
+	mode, retention := antiDeleteSettings(chat)
+	if mode == "" {
 		return
 	}
-	if len(info.Participants) == 0 {
+	cached, ok := peekMessage(chat, id)
+	if !ok || cached.Sender.User != revoker.User || time.Since(cached.Time) > retention {
 		return
 	}
-	winner := info.Participants[rand.Intn(len(info.Participants))]
-	sendText(chat, fmt.Sprintf("*[OdinBOT] SORTEIO!*\n\nO sortudo(a) e: @%s! Parabens!", winner.JID.User))
-}
 
-func cmdGroupStatus(chat types.JID) {
-	gJID := chat.String()
-	cfg := getGroupConfig(gJID)
-	boolStr := func(b bool) string {
-		if b {
-			return "ON"
+	text := cached.Text
+	if text == "" {
+		text = "(" + cached.Kind + ")"
+	}
+	notice := fmt.Sprintf("*[OdinBOT] Mensagem apagada*\n\nAutor: @%s\nEnviada as: %s\nConteudo: %s",
+		cached.Sender.User, cached.Time.Format("02/01 15:04:05"), text)
+	// Midias sao reenviadas com o conteudo original (mesmas chaves de midia)
+	var media *waE2E.Message
+	switch cached.Kind {
+	case KindImage, KindVideo, KindAudio, KindDocument, KindSticker, KindContact, KindLocation:
+		media = cached.Message
+	}
+
+	targets := []types.JID{chat}
+	if mode == "admins" {
+		notice = fmt.Sprintf("%s\nGrupo: %s", notice, getGroupName(chat))
+		targets = groupAdminJIDs(chat)
+	}
+	for _, t := range targets {
+		sendMention(t, notice, []string{cached.Sender.User})
+		if media != nil {
+			if _, err := client.SendMessage(context.Background(), t, media); err != nil {
+				fmt.Printf("[ERRO] Reenviar midia apagada: %v\n", err)
+			}
 		}
-		return "OFF"
 	}
-	msg := fmt.Sprintf(`*[OdinBOT] Status do Grupo:*
-
-- Bem-vindo: %s
-- Anti-link: %s
-- Anti-fake: %s
-- Anti-palavrao: %s
-- Auto-sticker: %s
-- Auto-download: %s
-- So admin: %s
-- NSFW: %s
-- Prefixo: %s
-- Ativo: %s`,
-		boolStr(cfg.Welcome), boolStr(cfg.Antilink), boolStr(cfg.Antifake),
-		boolStr(cfg.AntiPalavrao), boolStr(cfg.AutoSticker), boolStr(cfg.AutoDL),
-		boolStr(cfg.OnlyAdm), boolStr(cfg.NSFW), cfg.Prefix, boolStr(cfg.Active))
-	sendText(chat, msg)
+	logAction(chat.String(), BotName, cached.Sender.User, "antidelete", "Mensagem apagada: "+text, SourceAuto)
 }
 
-func cmdAddBadWord(chat types.JID, word string) {
-	if word == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #addpalavra <palavra>")
+// cmdAntiDelete: #antidelete grupo|admins|off [minutos]
+func cmdAntiDelete(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(strings.ToLower(args))
+	usage := fmt.Sprintf("*[OdinBOT]* Uso: #antidelete grupo|admins|off [minutos]\ngrupo: repostar no grupo | admins: enviar aos admins\nMinutos: por quanto tempo lembrar as mensagens (padrao %d, max %d)", defaultAntiDeleteMinutes, maxAntiDeleteMinutes)
+	if len(parts) == 0 || (parts[0] != "grupo" && parts[0] != "admins" && parts[0] != "off") {
+		sendText(chat, usage)
 		return
 	}
-	gJID := chat.String()
-	botData.mu.Lock()
-	botData.BadWords[gJID] = append(botData.BadWords[gJID], word)
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Palavra '%s' adicionada a lista proibida.", word))
-}
+	minutes := 0
+	if len(parts) > 1 {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n <= 0 || n > maxAntiDeleteMinutes {
+			sendText(chat, usage)
+			return
+		}
+		minutes = n
+	}
 
-func cmdDelBadWord(chat types.JID, word string) {
-	if word == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #delpalavra <palavra>")
-		return
+	mode := parts[0]
+	if mode == "off" {
+		mode = ""
 	}
-	gJID := chat.String()
 	botData.mu.Lock()
-	words := botData.BadWords[gJID]
-	for i, w := range words {
-		if strings.EqualFold(w, word) {
-			botData.BadWords[gJID] = append(words[:i], words[i+1:]...)
-			break
-		}
+	cfg.AntiDelete = mode
+	if minutes > 0 {
+		cfg.AntiDeleteMinutes = minutes
 	}
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* Palavra '%s' removida da lista.", word))
+	logAction(gJID, sender.User, "", "config", "antidelete "+strings.Join(parts, " "), SourceCommand)
+	if mode == "" {
+		sendText(chat, "*[OdinBOT]* Anti-delete desativado.")
+		return
+	}
+	forgetSpilledChat(chat)
+	_, retention := antiDeleteSettings(chat)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Anti-delete ativado (%s)! Mensagens apagadas ate %d min depois de enviadas serao mostradas.", parts[0], int(retention.Minutes())))
 }
 
-func cmdListBadWords(chat types.JID) {
-	gJID := chat.String()
+// ============================================================
+// Slow Mode
+// ============================================================
+
+var (
+	slowModeMu       sync.Mutex
+	slowModeLast     = make(map[string]time.Time) // grupo|usuario -> ultima mensagem aceita
+	slowModeNotified = make(map[string]bool)      // grupo|usuario -> ja recebeu aviso neste intervalo
+)
+
+// checkSlowMode apaga a mensagem se o membro ainda estiver no intervalo do slow mode.
+// Retorna true quando a mensagem foi descartada.
+func checkSlowMode(msg *events.Message) bool {
+	chat := msg.Info.Chat
+	sender := msg.Info.Sender
+	cfg := getGroupConfig(chat.String())
 	botData.mu.RLock()
-	words := botData.BadWords[gJID]
+	interval := time.Duration(cfg.SlowMode) * time.Second
 	botData.mu.RUnlock()
-	if len(words) == 0 {
-		sendText(chat, "*[OdinBOT]* Lista de palavras proibidas vazia.")
-		return
+	if interval <= 0 {
+		return false
 	}
-	msg := "*[OdinBOT] Palavras Proibidas:*\n\n"
-	for i, w := range words {
-		msg += fmt.Sprintf("%d. %s\n", i+1, w)
+
+	key := chat.String() + "|" + sender.User
+	slowModeMu.Lock()
+	last, ok := slowModeLast[key]
+	if !ok || time.Since(last) >= interval {
+		slowModeLast[key] = time.Now()
+		delete(slowModeNotified, key)
+		slowModeMu.Unlock()
+		return false
 	}
-	sendText(chat, msg)
+	wait := interval - time.Since(last)
+	notify := !slowModeNotified[key]
+	slowModeNotified[key] = true
+	slowModeMu.Unlock()
+
+	if isModerationExempt(chat, sender) {
+		return false
+	}
+	deleteMessage(chat, sender, msg.Info.ID)
+	if notify {
+		sendText(sender.ToNonAD(), fmt.Sprintf("*[OdinBOT]* O grupo %s esta em slow mode. Aguarde %d segundo(s) para enviar outra mensagem.",
+			getGroupName(chat), int(wait.Seconds())+1))
+	}
+	return true
 }
 
-func cmdAddNote(chat types.JID, text string) {
-	if text == "" {
-		sendText(chat, "*[OdinBOT]* Uso: #anotar <texto>")
-		return
+func cmdSlowMode(chat types.JID, sender types.JID, args string) {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	seconds := 0
+	if arg != "off" && arg != "0" {
+		n, err := strconv.Atoi(strings.TrimSuffix(arg, "s"))
+		if err != nil || n < 0 || n > 3600 {
+			sendText(chat, "*[OdinBOT]* Uso: #slowmode <segundos (1-3600)|off>")
+			return
+		}
+		seconds = n
 	}
 	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
 	botData.mu.Lock()
-	botData.Notes[gJID] = append(botData.Notes[gJID], text)
+	cfg.SlowMode = seconds
 	botData.mu.Unlock()
 	saveBotData()
-	sendText(chat, "*[OdinBOT]* Nota adicionada!")
+	if seconds == 0 {
+		logAction(gJID, sender.User, "", "config", "slowmode off", SourceCommand)
+		sendText(chat, "*[OdinBOT]* Slow mode desativado!")
+		return
+	}
+	logAction(gJID, sender.User, "", "config", fmt.Sprintf("slowmode %ds", seconds), SourceCommand)
+	sendText(chat, fmt.Sprintf("*[OdinBOT]* Slow mode ativado: 1 mensagem a cada %d segundo(s) por membro. Admins e cargos estao isentos.", seconds))
 }
 
-func cmdShowNotes(chat types.JID) {
-	gJID := chat.String()
-	botData.mu.RLock()
-	notes := botData.Notes[gJID]
-	botData.mu.RUnlock()
-	if len(notes) == 0 {
-		sendText(chat, "*[OdinBOT]* Nenhuma anotacao.")
+// ============================================================
+// Reactions
+// ============================================================
+
+func handleReaction(chat types.JID, sender types.JID, reaction *waE2E.ReactionMessage) {
+	msgID := reaction.GetKey().GetID()
+	if msgID == "" {
 		return
 	}
-	msg := "*[OdinBOT] Anotacoes:*\n\n"
-	for i, n := range notes {
-		msg += fmt.Sprintf("%d. %s\n", i+1, n)
+	if registerVoteKickReaction(chat, sender, msgID, reaction.GetText()) {
+		return
 	}
-	sendText(chat, msg)
+	handleModerationReaction(chat, sender, reaction)
 }
 
-func cmdDelNote(chat types.JID, idx string) {
-	gJID := chat.String()
-	var i int
-	fmt.Sscanf(idx, "%d", &i)
-	i--
-	botData.mu.Lock()
-	notes := botData.Notes[gJID]
-	if i >= 0 && i < len(notes) {
-		botData.Notes[gJID] = append(notes[:i], notes[i+1:]...)
-		botData.mu.Unlock()
-		saveBotData()
-		sendText(chat, "*[OdinBOT]* Nota removida!")
-	} else {
-		botData.mu.Unlock()
-		sendText(chat, "*[OdinBOT]* Numero da nota invalido.")
-	}
+// Acoes disponiveis por reacao e o comando cuja permissao o admin precisa ter
+var reactionActionCommands = map[string]string{
+	"apagar":   "deletar",
+	"advertir": "advertir",
+	"mutar":    "mute",
+	"banir":    "ban",
 }
 
-func cmdGroupInfo(chat types.JID) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
-		sendText(chat, "*[OdinBOT]* Erro ao obter info do grupo.")
+var defaultReactionActions = map[string]string{
+	"🗑": "apagar",
+	"⚠": "advertir",
+	"🔇": "mutar",
+	"⛔": "banir",
+}
+
+// normalizeEmoji remove o seletor de variacao (U+FE0F), que alguns celulares enviam e outros nao.
+func normalizeEmoji(e string) string {
+	return strings.ReplaceAll(strings.TrimSpace(e), "\uFE0F", "")
+}
+
+// handleModerationReaction executa a acao configurada para o emoji, se quem reagiu
+// tiver a mesma permissao exigida pelo comando equivalente.
+func handleModerationReaction(chat types.JID, reactor types.JID, reaction *waE2E.ReactionMessage) {
+	emoji := normalizeEmoji(reaction.GetText())
+	if emoji == "" {
 		return
 	}
-	admins := 0
-	for _, p := range info.Participants {
-		if p.IsAdmin || p.IsSuperAdmin {
-			admins++
+	cfg := getGroupConfig(chat.String())
+	botData.mu.RLock()
+	action, ok := cfg.ReactionActions[emoji]
+	botData.mu.RUnlock()
+	if !ok {
+		return
+	}
+	cmd := reactionActionCommands[action]
+	// Mesmas travas do comando equivalente: #bloquearcmd, #manutencao e cargos
+	if disabled, _ := isCommandDisabled(chat.String(), true, cmd); disabled {
+		return
+	}
+	if !isOwnerNumber(reactor.User) && !canRunCommand(chat, reactor, cmd) {
+		return
+	}
+	if action != "mutar" && !isBotAdmin(chat) {
+		sendText(chat, "*[OdinBOT]* Preciso ser admin para moderar por reacao.")
+		return
+	}
+
+	key := reaction.GetKey()
+	id := types.MessageID(key.GetID())
+	target, found := lookupMessage(chat, id)
+	if !found {
+		author, err := types.ParseJID(key.GetParticipant())
+		if err != nil || author.User == "" {
+			return
 		}
+		target = StoredMessage{ID: id, Chat: chat, Sender: author}
+	}
+	author := target.Sender
+	if author.User == reactor.User || (client.Store.ID != nil && author.User == client.Store.ID.User) {
+		return
+	}
+	if action != "apagar" && isOwnerNumber(author.User) {
+		sendText(chat, "*[OdinBOT]* Nao posso punir o dono!")
+		return
+	}
+	// Mesma regra dos comandos: nada contra cargo igual ou maior
+	if !outranks(chat, reactor, author) {
+		sendMention(chat, fmt.Sprintf("*[OdinBOT]* @%s, voce nao pode moderar por reacao alguem com cargo igual ou maior que o seu.", reactor.User), []string{reactor.User})
+		return
 	}
-	msg := fmt.Sprintf(`*[OdinBOT] Info do Grupo:*
 
-- Nome: %s
-- Membros: %d
-- Admins: %d
-- Descricao: %s`,
-		info.Name, len(info.Participants), admins, info.Topic)
-	sendText(chat, msg)
+	reason := "Via reacao"
+	if target.Text != "" {
+		snippet := []rune(target.Text)
+		if len(snippet) > 100 {
+			snippet = append(snippet[:100], []rune("...")...)
+		}
+		reason = "Via reacao: " + string(snippet)
+	}
+	switch action {
+	case "apagar":
+		deleteMessage(chat, author, id)
+		logAction(chat.String(), reactor.User, author.User, "deletar", reason, SourceCommand)
+		sendMention(chat, fmt.Sprintf("*[OdinBOT]* Mensagem de @%s apagada por @%s.", author.User, reactor.User), []string{author.User, reactor.User})
+	case "advertir":
+		deleteMessage(chat, author, id)
+		warnUser(chat, author, reactor.User, reason, SourceCommand)
+	case "mutar":
+		muteUser(chat, author, reactor.User, SourceCommand)
+	case "banir":
+		deleteMessage(chat, author, id)
+		banUser(chat, author, reactor.User, reason, SourceCommand)
+	}
 }
 
-func cmdListAdmins(chat types.JID) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
-		return
+func formatReactionActions(actions map[string]string) string {
+	if actions == nil {
+		return "desativado"
 	}
-	msg := "*[OdinBOT] Admins:*\n\n"
-	var mentions []string
-	for _, p := range info.Participants {
-		if p.IsAdmin || p.IsSuperAdmin {
-			role := "Admin"
-			if p.IsSuperAdmin {
-				role = "Super Admin"
+	var out []string
+	for _, a := range []string{"apagar", "advertir", "mutar", "banir"} {
+		for emoji, act := range actions {
+			if act == a {
+				out = append(out, emoji+" "+a)
 			}
-			msg += fmt.Sprintf("- @%s (%s)\n", p.JID.User, role)
-			mentions = append(mentions, p.JID.User)
 		}
 	}
-	sendMention(chat, msg, mentions)
+	if len(out) == 0 {
+		return "nenhuma reacao configurada"
+	}
+	return strings.Join(out, "\n")
 }
 
-func cmdRoleta(chat types.JID) {
-	info, err := client.GetGroupInfo(context.Background(), chat)
-	if err != nil {
+// cmdReactionActions: #reacaomod on|off | <emoji> <apagar|advertir|mutar|banir|off>
+func cmdReactionActions(chat types.JID, sender types.JID, args string) {
+	gJID := chat.String()
+	cfg := getGroupConfig(gJID)
+	parts := strings.Fields(args)
+	usage := "Uso: #reacaomod on|off | #reacaomod <emoji> <apagar|advertir|mutar|banir|off>\nPadrao: 🗑️ apagar, ⚠️ advertir, 🔇 mutar, ⛔ banir"
+
+	switch {
+	case len(parts) == 0:
+		botData.mu.RLock()
+		current := formatReactionActions(cfg.ReactionActions)
+		botData.mu.RUnlock()
+		sendText(chat, "*[OdinBOT] Moderacao por reacao:*\n\n"+current+"\n\n"+usage)
 		return
-	}
-	var nonAdmin []types.JID
-	for _, p := range info.Participants {
-		if !p.IsAdmin && !p.IsSuperAdmin && !isOwnerNumber(p.JID.User) {
-			nonAdmin = append(nonAdmin, p.JID)
+	case len(parts) == 1 && (parts[0] == "on" || parts[0] == "off"):
+		botData.mu.Lock()
+		if parts[0] == "on" {
+			cfg.ReactionActions = make(map[string]string)
+			for e, a := range defaultReactionActions {
+				cfg.ReactionActions[e] = a
+			}
+		} else {
+			cfg.ReactionActions = nil
 		}
-	}
-	if len(nonAdmin) == 0 {
-		sendText(chat, "*[OdinBOT]* Nenhum membro para a roleta.")
+		botData.mu.Unlock()
+	case len(parts) == 2:
+		emoji, action := normalizeEmoji(parts[0]), strings.ToLower(parts[1])
+		if _, ok := reactionActionCommands[action]; !ok && action != "off" {
+			sendText(chat, "*[OdinBOT]* "+usage)
+			return
+		}
+		botData.mu.Lock()
+		if action == "off" {
+			delete(cfg.ReactionActions, emoji)
+		} else {
+			if cfg.ReactionActions == nil {
+				cfg.ReactionActions = make(map[string]string)
+			}
+			cfg.ReactionActions[emoji] = action
+		}
+		botData.mu.Unlock()
+	default:
+		sendText(chat, "*[OdinBOT]* "+usage)
 		return
 	}
-	victim := nonAdmin[rand.Intn(len(nonAdmin))]
-	sendText(chat, fmt.Sprintf("*[OdinBOT] ROLETA RUSSA!*\n\nA bala acertou @%s!", victim.User))
-	removeMember(chat, victim)
+	saveBotData()
+	logAction(gJID, sender.User, "", "config", "reacaomod "+strings.Join(parts, " "), SourceCommand)
+	botData.mu.RLock()
+	current := formatReactionActions(cfg.ReactionActions)
+	botData.mu.RUnlock()
+	sendText(chat, "*[OdinBOT]* Moderacao por reacao atualizada:\n\n"+current)
 }
 
 // ============================================================
-// Blacklist Commands
+// General Commands
 // ============================================================
 
-func cmdAddBlacklist(chat types.JID, sender types.JID, number string) {
-	number = strings.TrimSpace(number)
-	botData.mu.Lock()
-	botData.Blacklist[number] = BlacklistEntry{
-		Number:  number,
-		Reason:  "Adicionado manualmente",
-		Date:    time.Now().Format("2006-01-02"),
-		AddedBy: sender.User,
-	}
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s adicionado a lista negra.", number))
+type menuItem struct {
+	Cmd  string
+	Text string
 }
 
-func cmdRemoveBlacklist(chat types.JID, number string) {
-	number = strings.TrimSpace(number)
-	botData.mu.Lock()
-	delete(botData.Blacklist, number)
-	botData.mu.Unlock()
-	saveBotData()
-	sendText(chat, fmt.Sprintf("*[OdinBOT]* %s removido da lista negra.", number))
+type menuSection struct {
+	Title string
+	Items []menuItem
 }
 
-func cmdShowBlacklist(chat types.JID) {
-	botData.mu.RLock()
-	defer botData.mu.RUnlock()
-	if len(botData.Blacklist) == 0 {
-		sendText(chat, "*[OdinBOT]* Lista negra vazia.")
-		return
+var menuSections = []menuSection{
+	{"GERAL", []menuItem{
+		{"menu", "{p}menu - Este menu"},
+		{"ping", "{p}ping - Testar bot"},
+		{"info", "{p}info - Info do bot"},
+		{"dono", "{p}dono - Info do dono"},
+		{"help", "{p}ajuda - Como usar"},
+		{"alugar", "{p}alugar - Info aluguel"},
+	}},
+	{"FIGURINHAS", []menuItem{
+		{"sticker", "{p}s / {p}fig - Criar figurinha"},
+		{"toimg", "{p}toimg - Figurinha para imagem"},
+	}},
+	{"UTILIDADES", []menuItem{
+		{"traduzir", "{p}traduzir - Traduzir texto"},
+		{"clima", "{p}clima - Previsao do tempo"},
+		{"signo", "{p}signo - Horoscopo"},
+		{"calculadora", "{p}calcular - Calculadora"},
+		{"sn", "{p}sn - Sim ou Nao"},
+		{"sorte", "{p}sorte - Sorte do dia"},
+		{"cantadas", "{p}cantadas - Cantada aleatoria"},
+		{"fatos", "{p}fatos - Fato aleatorio"},
+		{"conselho", "{p}conselho - Conselho aleatorio"},
+	}},
+	{"GRUPO", []menuItem{
+		{"regras", "{p}regras - Regras do grupo"},
+		{"perfil", "{p}perfil - Seu perfil"},
+		{"rankativo", "{p}rankativos - Rank de ativos"},
+		{"afk", "{p}afk - Ficar ausente"},
+		{"ativo", "{p}ativo - Voltar da ausencia"},
+		{"report", "{p}report - Denunciar mensagem (responda a ela)"},
+		{"votekick", "{p}votekick - Votacao para remover membro"},
+		{"horario", "{p}horario - Horario de funcionamento"},
+	}},
+	{"JOGOS", []menuItem{
+		{"ppt", "{p}ppt - Pedra Papel Tesoura"},
+		{"chance", "{p}chance - Porcentagem"},
+		{"moedas", "{p}moedas - Cara ou Coroa"},
+		{"dado", "{p}dado - Jogar dado"},
+		{"simi", "{p}simi - Conversar com o bot"},
+	}},
+}
+
+var adminMenuSection = menuSection{"ADM", []menuItem{
+	{"ban", "{p}ban - Banir membro"},
+	{"tempban", "{p}tempban - Banir por um tempo"},
+	{"listanegragp", "{p}listanegragp / {p}tirardalistagp - Lista negra do grupo"},
+	{"advertir", "{p}advertir - Advertir"},
+	{"checkwarnings", "{p}checkwarnings - Ver warns"},
+	{"removewarnings", "{p}removewarnings - Remover warn"},
+	{"clearwarnings", "{p}clearwarnings - Limpar warns"},
+	{"mute", "{p}mute / {p}desmute - Mutar"},
+	{"promover", "{p}promover / {p}rebaixar"},
+	{"protegeradm", "{p}protegeradm - Admins protegidos"},
+	{"avisoadm", "{p}avisoadm - Avisar promocoes/rebaixamentos"},
+	{"bemvindo", "{p}bemvindo - Ativar/desativar"},
+	{"antilink", "{p}antilink - Anti-link"},
+	{"antifake", "{p}antifake - Anti-fake"},
+	{"antipalavra", "{p}antipalavra - Anti-palavrao"},
+	{"autosticker", "{p}autosticker - Auto-figurinha"},
+	{"so_adm", "{p}so_adm - Modo admin"},
+	{"antimidia", "{p}antimidia - Bloquear figurinha, audio, documento..."},
+	{"antitrava", "{p}antitrava - Anti-trava"},
+	{"antimarcar", "{p}antimarcar - Limite de marcacoes"},
+	{"antidelete", "{p}antidelete - Mostrar mensagens apagadas"},
+	{"reacaomod", "{p}reacaomod - Moderar reagindo com emoji"},
+	{"slowmode", "{p}slowmode - Intervalo entre mensagens"},
+	{"fechargp", "{p}fechargp / {p}abrirgp"},
+	{"sethorario", "{p}sethorario / {p}horariomsg - Modo noturno"},
+	{"travarconfig", "{p}travarconfig / {p}destravarconfig - Travar nome e configs"},
+	{"nomegp", "{p}nomegp - Nome do grupo"},
+	{"descgp", "{p}descgp - Descricao"},
+	{"linkgp", "{p}linkgp - Link do grupo"},
+	{"tagall", "{p}tagall - Marcar todos"},
+	{"deletar", "{p}deletar - Apagar mensagem respondida"},
+	{"totag", "{p}totag - Tag oculta"},
+	{"sorteio", "{p}sorteio - Sortear membro"},
+	{"roleta", "{p}roleta - Roleta russa"},
+	{"status", "{p}status - Status do grupo"},
+	{"admins", "{p}admins - Listar admins"},
+	{"grupoinfo", "{p}grupoinfo - Info do grupo"},
+	{"addpalavra", "{p}addpalavra / {p}delpalavra"},
+	{"anotar", "{p}anotar / {p}anotacao"},
+	{"banghost", "{p}banghost - Banir ghosts"},
+	{"banfakes", "{p}banfakes - Banir fakes"},
+	{"logs", "{p}logs - Historico de moderacao"},
+	{"cargos", "{p}cargos - Cargos do grupo"},
+	{"permcmd", "{p}permcmd - Cargo minimo por comando"},
+	{"block", "{p}bloquearcmd / {p}liberarcmd"},
+	{"setmsg", "{p}setmsg / {p}vermsg / {p}resetmsg - Mensagens de remocao"},
+	{"reports", "{p}reports / {p}resolver - Denuncias"},
+	{"apelacoes", "{p}apelacoes / {p}aceitarapelo / {p}negarapelo"},
+	{"relatoriosgp", "{p}relatoriosgp - Grupo que recebe denuncias"},
+	{"votekickcfg", "{p}votekickcfg - Quorum/porcentagem do votekick"},
+}}
+
+var ownerMenuSection = menuSection{"DONO", []menuItem{
+	{"aluguel", "{p}aluguel - Gerenciar aluguel"},
+	{"verificar_aluguel", "{p}verificar_aluguel - Ver alugueis"},
+	{"bcaluguel", "{p}bcaluguel - BC alugueis"},
+	{"bc", "{p}bc - Broadcast geral"},
+	{"join", "{p}join - Entrar em grupo"},
+	{"sairgp", "{p}sairgp - Sair do grupo"},
+	{"nuke", "{p}nuke - Nuke grupo"},
+	{"grupos", "{p}grupos - Listar grupos"},
+	{"cargo", "{p}cargo - Definir cargo"},
+	{"listanegra", "{p}listanegra - Lista negra"},
+	{"tirardalista", "{p}tirardalista - Remover da lista"},
+	{"varrerlista", "{p}varrerlista - Remover a lista negra de todos os grupos"},
+	{"exportlista", "{p}exportlista / {p}importlista - Compartilhar lista negra"},
+	{"listafonte", "{p}listafonte - Listas negras sincronizadas"},
+	{"logs", "{p}logs global - Historico de todos os grupos"},
+	{"antiligar", "{p}antiligar - Rejeitar/bloquear ligacoes"},
+	{"cachemsg", "{p}cachemsg - Cache de mensagens recentes"},
+	{"spamcruzado", "{p}spamcruzado - Spam repetido em varios grupos"},
+	{"manutencao", "{p}manutencao - Desativar comando em todos os grupos"},
+	{"apelacoes", "{p}apelacoes - Apelacoes da lista negra"},
+}}
+
+func renderMenuSection(section menuSection, groupJID string, isGroup bool, prefix string) string {
+	out := ""
+	for _, item := range section.Items {
+		if disabled, _ := isCommandDisabled(groupJID, isGroup, item.Cmd); disabled {
+			continue
+		}
+		out += "\n" + strings.ReplaceAll(item.Text, "{p}", prefix)
 	}
-	msg := "*[OdinBOT] Lista Negra:*\n\n"
-	i := 1
-	for _, b := range botData.Blacklist {
-		msg += fmt.Sprintf("%d. %s - %s (%s)\n", i, b.Number, b.Reason, b.Date)
-		i++
+	if out == "" {
+		return ""
 	}
-	sendText(chat, msg)
+	return fmt.Sprintf("\n\n*--- %s ---*", section.Title) + out
 }
 
-// ============================================================
-// General Commands
-// ============================================================
-
 func cmdMenu(chat types.JID, sender types.JID, isOwner bool, isGroup bool) {
-	prefix := getPrefix(chat.String())
+	gJID := chat.String()
+	prefix := getPrefix(gJID)
 	menu := fmt.Sprintf(`*╔══════════════════╗*
 *║     %s - MENU     ║*
 *╚══════════════════╝*
 
 *Dono: %s*
-*Prefixo: %s*
-
-*--- GERAL ---*
-%smenu - Este menu
-%sping - Testar bot
-%sinfo - Info do bot
-%sdono - Info do dono
-%sajuda - Como usar
-%salugar - Info aluguel
-
-*--- FIGURINHAS ---*
-%ss / %sfig - Criar figurinha
-%stoimg - Figurinha para imagem
-
-*--- UTILIDADES ---*
-%straduzir - Traduzir texto
-%sclima - Previsao do tempo
-%ssigno - Horoscopo
-%scalcular - Calculadora
-%ssn - Sim ou Nao
-%ssorte - Sorte do dia
-%scantadas - Cantada aleatoria
-%sfatos - Fato aleatorio
-%sconselho - Conselho aleatorio
-
-*--- GRUPO ---*
-%sregras - Regras do grupo
-%sadmins - Listar admins
-%sperfil - Seu perfil
-%srankativos - Rank de ativos
-%safk - Ficar ausente
-%sativo - Voltar da ausencia
-
-*--- JOGOS ---*
-%sppt - Pedra Papel Tesoura
-%schance - Porcentagem
-%smoedas - Cara ou Coroa
-%sdado - Jogar dado`, BotName, OwnerName, prefix,
-		prefix, prefix, prefix, prefix, prefix, prefix,
-		prefix, prefix, prefix,
-		prefix, prefix, prefix, prefix, prefix, prefix, prefix, prefix, prefix,
-		prefix, prefix, prefix, prefix, prefix, prefix,
-		prefix, prefix, prefix, prefix)
-
-	if isGroup && (isOwner || isGroupAdmin(chat, sender)) {
-		menu += fmt.Sprintf(`
-
-*--- ADM ---*
-%sban - Banir membro
-%sadvertir - Advertir
-%scheckwarnings - Ver warns
-%sremovewarnings - Remover warn
-%sclearwarnings - Limpar warns
-%smute / %sdesmute - Mutar
-%spromover / %srebaixar
-%sbemvindo - Ativar/desativar
-%santilink - Anti-link
-%santifake - Anti-fake
-%santipalavra - Anti-palavrao
-%sautosticker - Auto-figurinha
-%sso_adm - Modo admin
-%sfechargp / %sabrirgp
-%snomegp - Nome do grupo
-%sdescgp - Descricao
-%slinkgp - Link do grupo
-%stagall - Marcar todos
-%stotag - Tag oculta
-%ssorteio - Sortear membro
-%sroleta - Roleta russa
-%sstatus - Status do grupo
-%sadmins - Listar admins
-%sgrupoinfo - Info do grupo
-%saddpalavra / %sdelpalavra
-%sanotar / %sanotacao
-%sbanghost - Banir ghosts
-%sbanfakes - Banir fakes`, prefix, prefix, prefix, prefix, prefix,
-			prefix, prefix, prefix, prefix, prefix, prefix,
-			prefix, prefix, prefix, prefix, prefix, prefix,
-			prefix, prefix, prefix, prefix, prefix, prefix, prefix,
-			prefix, prefix, prefix, prefix, prefix, prefix, prefix)
+*Prefixo: %s*`, BotName, OwnerName, prefix)
+
+	for _, section := range menuSections {
+		menu += renderMenuSection(section, gJID, isGroup, prefix)
+	}
+
+	if isGroup && (isOwner || hasAnyPermission(chat, sender)) {
+		menu += renderMenuSection(adminMenuSection, gJID, isGroup, prefix)
 	}
 
 	if isOwner {
-		menu += fmt.Sprintf(`
-
-*--- DONO ---*
-%saluguel - Gerenciar aluguel
-%sverificar_aluguel - Ver alugueis
-%sbcaluguel - BC alugueis
-%sbc - Broadcast geral
-%sjoin - Entrar em grupo
-%ssairgp - Sair do grupo
-%snuke - Nuke grupo
-%sgrupos - Listar grupos
-%scargo - Definir cargo
-%slistanegra - Lista negra
-%stirardalista - Remover da lista`, prefix, prefix, prefix, prefix, prefix,
-			prefix, prefix, prefix, prefix, prefix, prefix)
+		menu += renderMenuSection(ownerMenuSection, gJID, isGroup, prefix)
 	}
 
 	sendText(chat, menu)
@@ -2129,6 +7417,7 @@ func loadBotData() *BotData {
 		MutedUsers: make(map[string]map[string]bool),
 		AfkUsers:   make(map[string]string),
 		Roles:      make(map[string]map[string]string),
+		CallCounts: make(map[string]int),
 	}
 
 	filePath := filepath.Join(dataDir, "botdata.json")
@@ -2168,6 +7457,9 @@ func loadBotData() *BotData {
 	if data.Roles == nil {
 		data.Roles = make(map[string]map[string]string)
 	}
+	if data.CallCounts == nil {
+		data.CallCounts = make(map[string]int)
+	}
 
 	fmt.Printf("[INFO] Dados carregados: %d grupos, %d alugueis\n", len(data.Groups), len(data.Rentals))
 	return data
@@ -16,6 +16,7 @@ import {
   X,
   ChevronRight,
   Wifi,
+  History,
 } from "lucide-react"
 import { useState } from "react"
 
@@ -26,6 +27,7 @@ const navItems = [
   { href: "/rentals", label: "Alugueis", icon: CreditCard },
   { href: "/warnings", label: "Advertencias", icon: AlertTriangle },
   { href: "/blacklist", label: "Lista Negra", icon: Ban },
+  { href: "/logs", label: "Historico", icon: History },
   { href: "/scheduled", label: "Agendamentos", icon: Clock },
   { href: "/settings", label: "Configuracoes", icon: Settings },
 ]
@@ -20,18 +20,18 @@ apt-get update -y
 apt-get install -y curl wget git build-essential gcc sqlite3
 
 echo ""
-echo "[2/7] Instalando Go 1.22.5 (limpo)..."
+echo "[2/7] Instalando Go 1.24.11 (limpo)..."
 # SEMPRE remove instalacao antiga para evitar conflitos de runtime
 echo "  -> Removendo Go antigo (se existir)..."
 rm -rf /usr/local/go
-rm -f /tmp/go1.22.5.linux-amd64.tar.gz
+rm -f /tmp/go1.24.11.linux-amd64.tar.gz
 
-echo "  -> Baixando Go 1.22.5..."
-wget -q --show-progress https://go.dev/dl/go1.22.5.linux-amd64.tar.gz -O /tmp/go1.22.5.linux-amd64.tar.gz
+echo "  -> Baixando Go 1.24.11..."
+wget -q --show-progress https://go.dev/dl/go1.24.11.linux-amd64.tar.gz -O /tmp/go1.24.11.linux-amd64.tar.gz
 
 echo "  -> Extraindo..."
-tar -C /usr/local -xzf /tmp/go1.22.5.linux-amd64.tar.gz
-rm -f /tmp/go1.22.5.linux-amd64.tar.gz
+tar -C /usr/local -xzf /tmp/go1.24.11.linux-amd64.tar.gz
+rm -f /tmp/go1.24.11.linux-amd64.tar.gz
 
 # Garantir PATH
 export PATH=/usr/local/go/bin:$PATH
@@ -51,6 +51,16 @@ export interface BlacklistEntry {
   addedBy: string
 }
 
+export interface AuditEntry {
+  time: string
+  actor: string
+  target: string
+  group: string
+  action: string
+  reason: string
+  source: "comando" | "automatico" | "painel"
+}
+
 export interface ScheduledMessage {
   id: string
   groupJid: string
@@ -216,6 +226,31 @@ export function saveSettings(settings: BotSettings): void {
   safeSet(STORAGE_KEYS.settings, settings)
 }
 
+// Audit log (served from the bot's data/auditlog.jsonl)
+export async function getAuditLog(params: { group?: string; action?: string; limit?: number } = {}): Promise<AuditEntry[]> {
+  const query = new URLSearchParams()
+  if (params.group) query.set("group", params.group)
+  if (params.action) query.set("action", params.action)
+  if (params.limit) query.set("limit", String(params.limit))
+  try {
+    const res = await fetch(`/api/logs?${query.toString()}`)
+    return res.ok ? await res.json() : []
+  } catch {
+    return []
+  }
+}
+export async function logPanelAction(entry: { action: string; target?: string; group?: string; reason?: string }): Promise<void> {
+  try {
+    await fetch("/api/logs", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(entry),
+    })
+  } catch {
+    // Log unavailable
+  }
+}
+
 // Generate unique ID
 export function generateId(): string {
   return Date.now().toString(36) + Math.random().toString(36).substring(2, 9)